@@ -0,0 +1,261 @@
+package ase
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"unicode/utf16"
+)
+
+var ErrInvalidACO = errors.New("ase: file not an ACO file")
+
+//	ACO File Spec https://www.adobe.com/devnet-apps/photoshop/fileformatashtml/#50577411_pgfId-1055819
+
+// Photoshop color space ids.
+const (
+	acoRGB  = uint16(0)
+	acoHSB  = uint16(1)
+	acoCMYK = uint16(2)
+	acoLab  = uint16(7)
+	acoGray = uint16(8)
+)
+
+// A color as stored in an ACO file, a color space and four components.
+type acoColor struct {
+	Space uint16
+	Data  [4]uint16
+}
+
+// Decodes a Photoshop color swatch (.aco) input into an ASE of loose colors.
+//
+// Version 2 files carry names, version 1 files don't. RGB, CMYK, Lab and
+// grayscale colors map onto the matching ASE models, HSB colors are
+// converted to RGB. Other color spaces, such as Pantone or Toyo, fail with
+// ErrInvalidColorModel. ACO colors have no type, they are all Normal.
+func DecodeACO(r io.Reader) (ase ASE, err error) {
+	var version uint16
+	if err = binary.Read(r, binary.BigEndian, &version); err != nil {
+		return
+	}
+
+	//	version 1 may be followed by the same colors again as version 2
+	if version == 1 {
+		if ase.Colors, err = readACOSection(r, false); err != nil {
+			return
+		}
+
+		if err = binary.Read(r, binary.BigEndian, &version); err == io.EOF {
+			return ase, nil
+		} else if err != nil {
+			return
+		}
+	}
+
+	if version != 2 {
+		return ase, ErrInvalidACO
+	}
+
+	ase.Colors, err = readACOSection(r, true)
+
+	return
+}
+
+// Helper function that decodes an ACO file into an ASE.
+func DecodeACOFile(file string) (ase ASE, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	return DecodeACO(f)
+}
+
+// Encodes the colors of an ASE, loose and grouped, as a Photoshop color swatch
+// (.aco) file. Both a version 1 and a version 2 section are written, so that
+// old and new readers alike find the colors. ACO files have no groups and no
+// color types, those are lost.
+func EncodeACO(ase ASE, w io.Writer) (err error) {
+	var colors []acoColor
+	var names []string
+
+	ase.eachColor(func(group *Group, color *Color) {
+		if err != nil {
+			return
+		}
+
+		var c acoColor
+		if c, err = toACO(color); err != nil {
+			err = fmt.Errorf("ase: color %q: %w", color.Name, err)
+			return
+		}
+		colors = append(colors, c)
+		names = append(names, color.Name)
+	})
+	if err != nil {
+		return
+	}
+
+	if len(colors) > math.MaxUint16 {
+		return fmt.Errorf("ase: %d colors, an ACO file holds at most %d", len(colors), math.MaxUint16)
+	}
+
+	for _, version := range []uint16{1, 2} {
+		if err = binary.Write(w, binary.BigEndian, []uint16{version, uint16(len(colors))}); err != nil {
+			return
+		}
+
+		for i, c := range colors {
+			if err = binary.Write(w, binary.BigEndian, c); err != nil {
+				return
+			}
+			if version == 1 {
+				continue
+			}
+
+			//	names are zero terminated and their length includes the terminator
+			name := append(utf16.Encode([]rune(names[i])), 0)
+			if err = binary.Write(w, binary.BigEndian, uint32(len(name))); err != nil {
+				return
+			}
+			if err = binary.Write(w, binary.BigEndian, name); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// Decodes the colors of a section, following its version number.
+func readACOSection(r io.Reader, named bool) (colors []Color, err error) {
+	var count uint16
+	if err = binary.Read(r, binary.BigEndian, &count); err != nil {
+		return
+	}
+
+	for i := 0; i < int(count); i++ {
+		var c acoColor
+		if err = binary.Read(r, binary.BigEndian, &c); err != nil {
+			return
+		}
+
+		var color Color
+		if color, err = fromACO(c); err != nil {
+			return
+		}
+
+		if named {
+			if color.Name, err = readACOName(r); err != nil {
+				return
+			}
+		}
+
+		colors = append(colors, color)
+	}
+
+	return
+}
+
+// Decodes a version 2 color name.
+func readACOName(r io.Reader) (name string, err error) {
+	var nameLen uint32
+	if err = binary.Read(r, binary.BigEndian, &nameLen); err != nil {
+		return
+	}
+	if nameLen == 0 {
+		return
+	}
+	if nameLen > maxNameLen+1 {
+		return "", ErrNameTooLong
+	}
+
+	units := make([]uint16, nameLen)
+	if err = binary.Read(r, binary.BigEndian, units); err != nil {
+		return
+	}
+
+	//	trim off the zero terminator
+	return string(utf16.Decode(units[:len(units)-1])), nil
+}
+
+// Converts an ACO color to an ASE color.
+//
+// RGB and HSB components go from 0 to 65535. CMYK components too, but
+// inverted: 0 is full ink. Lab lightness goes from 0 to 10000 and a and b are
+// signed and multiplied by 100. Gray goes from 0 to 10000, as ink, so 0 is
+// white.
+func fromACO(c acoColor) (color Color, err error) {
+	color.Type = Normal
+	v := func(i int) float64 {
+		return float64(c.Data[i]) / 0xffff
+	}
+
+	switch c.Space {
+	case acoRGB:
+		color.Model = RGB
+		color.Values = []float32{float32(v(0)), float32(v(1)), float32(v(2))}
+	case acoHSB:
+		r, g, b := hsbToRGB(v(0)*360, v(1), v(2))
+		color.Model = RGB
+		color.Values = []float32{float32(r), float32(g), float32(b)}
+	case acoCMYK:
+		color.Model = CMYK
+		color.Values = []float32{float32(1 - v(0)), float32(1 - v(1)), float32(1 - v(2)), float32(1 - v(3))}
+	case acoLab:
+		color.Model = LAB
+		color.Values = []float32{
+			float32(c.Data[0]) / 10000,
+			float32(int16(c.Data[1])) / 100,
+			float32(int16(c.Data[2])) / 100,
+		}
+	case acoGray:
+		color.Model = Gray
+		color.Values = []float32{1 - float32(c.Data[0])/10000}
+	default:
+		err = fmt.Errorf("%w: ACO color space %d", ErrInvalidColorModel, c.Space)
+	}
+
+	return
+}
+
+// Converts an ASE color to an ACO color, see fromACO.
+func toACO(color *Color) (c acoColor, err error) {
+	if err = color.checkValues(); err != nil {
+		return
+	}
+
+	v := color.Values
+	u16 := func(f float32) uint16 {
+		return uint16(math.Round(clip(float64(f)) * 0xffff))
+	}
+	s16 := func(f float32) uint16 {
+		return uint16(int16(math.Round(math.Max(-327.68, math.Min(327.67, float64(f))) * 100)))
+	}
+
+	switch color.Model {
+	case RGB:
+		c = acoColor{acoRGB, [4]uint16{u16(v[0]), u16(v[1]), u16(v[2])}}
+	case CMYK:
+		c = acoColor{acoCMYK, [4]uint16{u16(1 - v[0]), u16(1 - v[1]), u16(1 - v[2]), u16(1 - v[3])}}
+	case LAB:
+		c = acoColor{acoLab, [4]uint16{uint16(math.Round(clip(float64(v[0])) * 10000)), s16(v[1]), s16(v[2])}}
+	case Gray:
+		c = acoColor{acoGray, [4]uint16{uint16(math.Round((1 - clip(float64(v[0]))) * 10000))}}
+	}
+
+	return
+}
+
+// Converts HSB, hue in degrees and saturation and brightness going from 0 to 1,
+// to sRGB.
+func hsbToRGB(h, s, v float64) (r, g, b float64) {
+	f := func(n float64) float64 {
+		k := math.Mod(n+h/60, 6)
+		return v - v*s*math.Max(0, math.Min(k, math.Min(4-k, 1)))
+	}
+	return f(5), f(3), f(1)
+}
@@ -0,0 +1,77 @@
+package ase
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestACORoundTrip(t *testing.T) {
+	sampleAse := ASE{Colors: testColors, Groups: []Group{testGroup}}
+
+	b := new(bytes.Buffer)
+	if err := EncodeACO(sampleAse, b); err != nil {
+		t.Fatal(err)
+	}
+
+	ase, err := DecodeACO(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Groups get flattened, names, models and values survive.
+	expected := append(append([]Color{}, testColors...), testGroup.Colors...)
+	if len(ase.Colors) != len(expected) {
+		t.Fatal("expected", len(expected), "colors, got", len(ase.Colors))
+	}
+	for i, c := range ase.Colors {
+		if c.Name != expected[i].Name || c.Model != expected[i].Model || c.Type != Normal {
+			t.Errorf("expected %s %s, got %+v", expected[i].Name, expected[i].Model, c)
+		}
+		if !closeTo(c.Values, expected[i].Values, 0.01) {
+			t.Error("expected", expected[i].Values, "got", c.Values)
+		}
+	}
+}
+
+func TestDecodeACO(t *testing.T) {
+	// A version 1 file: HSB pure green, gray at 25% ink, and Lab.
+	v1 := []uint16{
+		1, 3,
+		acoHSB, 0xffff / 3, 0xffff, 0xffff, 0,
+		acoGray, 2500, 0, 0, 0,
+		acoLab, 5000, uint16(0x10000 - 2000), 3000, 0,
+	}
+	b := new(bytes.Buffer)
+	binary.Write(b, binary.BigEndian, v1)
+
+	ase, err := DecodeACO(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Color{
+		{Model: RGB, Values: []float32{0, 1, 0}},
+		{Model: Gray, Values: []float32{0.75}},
+		{Model: LAB, Values: []float32{0.5, -20, 30}},
+	}
+	for i, c := range ase.Colors {
+		if c.Model != expected[i].Model || !closeTo(c.Values, expected[i].Values, 0.001) || c.Name != "" {
+			t.Errorf("expected %s %v, got %+v", expected[i].Model, expected[i].Values, c)
+		}
+	}
+
+	// Pantone and friends aren't supported.
+	b.Reset()
+	binary.Write(b, binary.BigEndian, []uint16{1, 1, 3, 0, 0, 0, 0})
+	if _, err = DecodeACO(b); !errors.Is(err, ErrInvalidColorModel) {
+		t.Error("expected", ErrInvalidColorModel, "got", err)
+	}
+
+	b.Reset()
+	binary.Write(b, binary.BigEndian, []uint16{3, 0})
+	if _, err = DecodeACO(b); err != ErrInvalidACO {
+		t.Error("expected", ErrInvalidACO, "got", err)
+	}
+}
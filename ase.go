@@ -10,89 +10,192 @@ import (
 
 var (
 	ErrInvalidFile      = errors.New("ase: file not an ASE file")
-	ErrInvalidVersion   = errors.New("ase: version is not 1.0")
+	ErrInvalidVersion   = errors.New("ase: unsupported version")
 	ErrInvalidBlockType = errors.New("ase: invalid block type")
+	ErrInvalidBlockLen  = errors.New("ase: invalid block length")
+	ErrBlockTruncated   = errors.New("ase: block is shorter than its contents")
+	ErrBlockTrailing    = errors.New("ase: block has trailing bytes")
 )
 
 type ASE struct {
 	signature [4]uint8
-	version   [2]int16
 	numBlocks int32
 	Colors    []Color
 	Groups    []Group
+
+	// FileVersion is the version read by Decode and written by Encode.
+	// The zero value is written as 1.0.
+	FileVersion FileVersion
+
+	// Entries holds the document's top level colors, groups and raw blocks
+	// in file order, and is what Encode writes when it is set. Decode points
+	// each entry at the matching element of Colors or Groups, so changing a
+	// color or group in place shows through either view, while adding,
+	// removing or moving one is done on Entries. When Entries is empty,
+	// Encode writes Colors followed by Groups.
+	Entries []Entry
+}
+
+// An Entry is an item of an ASE document: a *Color, a *Group or a *RawBlock.
+type Entry interface {
+	write(w io.Writer) error
+	blockCount() int32
+}
+
+// FileVersion is the version of the ASE file format, stored as two 16 bit
+// numbers.
+type FileVersion struct {
+	Major int16
+	Minor int16
+}
+
+// The only version of the format Adobe has published.
+var Version1 = FileVersion{1, 0}
+
+// Returns the version in a human readable format, such as "1.0".
+func (v FileVersion) String() string {
+	return strconv.Itoa(int(v.Major)) + "." + strconv.Itoa(int(v.Minor))
+}
+
+// VersionPolicy decides which file versions the decoder accepts.
+type VersionPolicy int
+
+const (
+	// VersionStrict only accepts version 1.0.
+	VersionStrict VersionPolicy = iota
+	// VersionMajor accepts any 1.x version.
+	VersionMajor
+	// VersionAny accepts every version.
+	VersionAny
+)
+
+// Reports whether the policy accepts v.
+func (policy VersionPolicy) accepts(v FileVersion) bool {
+	switch policy {
+	case VersionAny:
+		return true
+	case VersionMajor:
+		return v.Major == Version1.Major
+	}
+	return v == Version1
 }
 
 //	ASE File Spec http://www.selapa.net/swatches/colors/fileformats.php#adobe_ase
 
 //	Decodes a valid ASE input.
 func Decode(r io.Reader) (ase ASE, err error) {
-	if err = ase.readSignature(r); err != nil {
-		return
-	}
-	if err = ase.readVersion(r); err != nil {
-		return
-	}
-	if err = ase.readNumBlocks(r); err != nil {
-		return
-	}
+	return DecodeWithOptions(r, DecoderOptions{})
+}
 
-	//	if we encounter groups, store a ref here
-	var g Group
+// DecoderOptions controls how strictly an ASE input is decoded.
+type DecoderOptions struct {
+	// Lenient skips blocks of an unknown type by their declared length
+	// instead of failing with ErrInvalidBlockType. Skipped blocks are kept
+	// as *RawBlock entries so a later Encode writes them back unchanged.
+	// Colors whose payload doesn't match their block length are kept the
+	// same way, with RawBlock.Err saying what was wrong, and decoding
+	// resumes at the next block.
+	Lenient bool
+
+	// Version decides which file versions are accepted. By default only
+	// 1.0 is, other versions fail with ErrInvalidVersion.
+	Version VersionPolicy
+}
 
-	//	itereate based on our block count
-	for i := 0; i < int(ase.numBlocks); i++ {
-		//	new block
-		b := block{}
+// Decodes an ASE input according to opts.
+func DecodeWithOptions(r io.Reader, opts DecoderOptions) (ase ASE, err error) {
+	d := NewDecoder(r)
+	d.DecoderOptions = opts
 
-		//	decode the block container
-		if err = b.Read(r); err != nil {
-			return
-		}
+	//	if we encounter groups, store a ref here
+	var g *Group
 
-		//	switch on block type
-		switch b.Type {
-		case colorEntry:
-			c := Color{}
-			if err = c.read(r); err != nil {
-				return
-			}
+	//	document order of the top level entries and of the current group's
+	//	entries
+	var entries, groupEntries []Entry
 
+	//	iterate over the decoded blocks
+	for d.Next() {
+		switch tok := d.Token().(type) {
+		case Color:
 			//	if we have a group, add color to the group
-			if g.Name != "" {
-				g.Colors = append(g.Colors, c)
+			if g != nil {
+				groupEntries = append(groupEntries, &tok)
 			} else {
-				//	color is not in a group. add to color slice
-				ase.Colors = append(ase.Colors, c)
+				entries = append(entries, &tok)
 			}
-
-			break
-		case groupStart:
+		case GroupStart:
 			//	new group
-			g = Group{}
-
-			//	read the group
-			if err = g.read(r); err != nil {
-				return
+			g = &Group{Name: tok.Name, RawStart: tok.Raw}
+			groupEntries = nil
+		case GroupEnd:
+			if g == nil {
+				g = &Group{}
 			}
+			g.RawEnd = tok.Raw
 
-			break
-		case groupEnd:
 			//	add the group to our ase struct
-			ase.Groups = append(ase.Groups, g)
+			g.fill(groupEntries)
+			entries = append(entries, g)
+
+			//	reset our group
+			g = nil
+			groupEntries = nil
+		case RawBlock:
+			//	keep blocks we could not or would not decode, in document order
+			if g != nil {
+				groupEntries = append(groupEntries, &tok)
+			} else {
+				entries = append(entries, &tok)
+			}
+		}
+	}
 
-			//	reset our group struct
-			g = Group{}
+	//	the header is known as soon as the first block was read
+	ase.signature = d.header.signature
+	ase.FileVersion = d.header.FileVersion
+	ase.numBlocks = d.header.numBlocks
 
-			break
-		default:
-			err = ErrInvalidBlockType
-			return
-		}
+	ase.fill(entries)
+
+	//	on errors, what was decoded so far is only kept in Colors and Groups
+	if err = d.Err(); err != nil {
+		ase.Entries = nil
 	}
 
 	return
 }
 
+// Sets Colors, Groups and Entries from entries, which are kept in order. The
+// colors and groups are copied into Colors and Groups, and Entries points at
+// the copies.
+func (ase *ASE) fill(entries []Entry) {
+	ase.Colors, ase.Groups, ase.Entries = nil, nil, nil
+
+	for _, entry := range entries {
+		switch entry := entry.(type) {
+		case *Color:
+			ase.Colors = append(ase.Colors, *entry)
+		case *Group:
+			ase.Groups = append(ase.Groups, *entry)
+		}
+	}
+
+	//	Colors and Groups are done growing, build the ordered view on top
+	var colors, groups int
+	for _, entry := range entries {
+		switch entry.(type) {
+		case *Color:
+			entry = &ase.Colors[colors]
+			colors++
+		case *Group:
+			entry = &ase.Groups[groups]
+			groups++
+		}
+		ase.Entries = append(ase.Entries, entry)
+	}
+}
+
 //	Helper function that decodes a file into an ASE.
 func DecodeFile(file string) (ase ASE, err error) {
 	//	open the file
@@ -106,24 +209,40 @@ func DecodeFile(file string) (ase ASE, err error) {
 }
 
 // Encodes an ASE into any `w` that satisfies the io.Writer interface.
+// The ASE is validated first, see ASE.Validate.
 func Encode(ase ASE, w io.Writer) (err error) {
+	return EncodeWithOptions(ase, w, EncoderOptions{})
+}
+
+// EncoderOptions controls how an ASE is encoded.
+type EncoderOptions struct {
+	// SkipValidation writes colors without checking them first. Colors
+	// with an unknown model or type still fail to encode, but nothing
+	// stops a wrong number of values from producing a corrupt file.
+	SkipValidation bool
+
+	// Version, when set, is written instead of the ASE's FileVersion.
+	Version FileVersion
+}
+
+// Encodes an ASE into `w` according to opts.
+func EncodeWithOptions(ase ASE, w io.Writer, opts EncoderOptions) (err error) {
+	if !opts.SkipValidation {
+		if err = ase.Validate(); err != nil {
+			return err
+		}
+	}
+
 	if err = ase.writeSignature(w); err != nil {
 		return err
 	}
-	if err = ase.writeVersion(w); err != nil {
+	if err = ase.writeVersion(w, opts.Version); err != nil {
 		return err
 	}
 	if err = ase.writeNumBlocks(w); err != nil {
 		return err
 	}
-	if err = ase.writeColors(w); err != nil {
-		return err
-	}
-	if err = ase.writeGroups(w); err != nil {
-		return err
-	}
-
-	return nil
+	return ase.writeEntries(w)
 }
 
 //	Returns the file signature in a human readable format.
@@ -133,7 +252,14 @@ func (ase *ASE) Signature() string {
 
 // Returns the file version in a human readable format.
 func (ase *ASE) Version() string {
-	return strconv.Itoa(int(ase.version[0])) + "." + strconv.Itoa(int(ase.version[1]))
+	return ase.FileVersion.String()
+}
+
+// Returns the number of blocks the header of the decoded file declares, or 0
+// when the ASE wasn't decoded from a file. Encode counts the blocks it writes
+// instead.
+func (ase *ASE) NumBlocks() int32 {
+	return ase.numBlocks
 }
 
 // Decodes the ASE's signature
@@ -152,14 +278,14 @@ func (ase *ASE) readSignature(r io.Reader) (err error) {
 }
 
 //	Decodes the ASE's version
-func (ase *ASE) readVersion(r io.Reader) (err error) {
+func (ase *ASE) readVersion(r io.Reader, policy VersionPolicy) (err error) {
 	// Read the version
-	if err = binary.Read(r, binary.BigEndian, &ase.version); err != nil {
+	if err = binary.Read(r, binary.BigEndian, &ase.FileVersion); err != nil {
 		return
 	}
 
-	// Checks version is 1.0
-	if ase.version[0] != 1 && ase.version[1] != 0 {
+	// Checks the version is one the policy accepts
+	if !policy.accepts(ase.FileVersion) {
 		return ErrInvalidVersion
 	}
 
@@ -177,9 +303,16 @@ func (ase *ASE) writeSignature(w io.Writer) (err error) {
 	return binary.Write(w, binary.BigEndian, signature)
 }
 
-// Encodes the ASE version
-func (ase *ASE) writeVersion(w io.Writer) (err error) {
-	version := [2]int16{1, 0}
+// Encodes the ASE version. A non zero override wins over the ASE's own version,
+// and 1.0 is written when neither is set.
+func (ase *ASE) writeVersion(w io.Writer, override FileVersion) (err error) {
+	version := ase.FileVersion
+	if override != (FileVersion{}) {
+		version = override
+	}
+	if version == (FileVersion{}) {
+		version = Version1
+	}
 	return binary.Write(w, binary.BigEndian, version)
 }
 
@@ -192,40 +325,51 @@ func (ase *ASE) writeNumBlocks(w io.Writer) (err error) {
 // Determines the numBlocks of an ASE on the fly rather than returning its `ase.numBlocks` attribute.
 // There is currently no mechanism in place to update numBlocks if a user adds or removes either colors, groups, or colors within groups.
 func (ase *ASE) calculateNumBlocks() (numBlocks int32) {
-	// A color has only one block.
-	colorBlocks := len(ase.Colors)
-
-	// A single group has a start block and an end block.
-	groupBlocks := len(ase.Groups) * 2
-
-	// Run a comprehension counting every color inside groups.
-	if groupBlocks != 0 {
-		for _, group := range ase.Groups {
-			colorBlocks += len(group.Colors)
-		}
+	// A color has only one block, a group has a start block, an end block
+	// and the blocks of its colors.
+	for _, entry := range ase.entries() {
+		numBlocks += entry.blockCount()
 	}
 
-	numBlocks = int32(colorBlocks + groupBlocks)
-
 	return
 }
 
-// Encode the data for ase.Colors according to the ASE spec.
-func (ase *ASE) writeColors(w io.Writer) (err error) {
-	for _, color := range ase.Colors {
-		if err = color.write(w); err != nil {
+// Encode the data for the colors, groups and raw blocks in document order.
+func (ase *ASE) writeEntries(w io.Writer) (err error) {
+	for _, entry := range ase.entries() {
+		if err = entry.write(w); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// Encode the data for ase.Groups according to the ASE spec.
-func (ase *ASE) writeGroups(w io.Writer) (err error) {
-	for _, group := range ase.Groups {
-		if err = group.write(w); err != nil {
-			return err
+// Returns the top level entries Encode would write, in order: Entries, or
+// Colors followed by Groups when it's empty.
+func (ase *ASE) entries() []Entry {
+	if len(ase.Entries) > 0 {
+		return ase.Entries
+	}
+
+	entries := make([]Entry, 0, len(ase.Colors)+len(ase.Groups))
+	for i := range ase.Colors {
+		entries = append(entries, &ase.Colors[i])
+	}
+	for i := range ase.Groups {
+		entries = append(entries, &ase.Groups[i])
+	}
+	return entries
+}
+
+// Calls fn for every color Encode would write, in order, along with the group
+// it belongs to or nil for loose colors.
+func (ase *ASE) eachColor(fn func(group *Group, color *Color)) {
+	for _, entry := range ase.entries() {
+		switch entry := entry.(type) {
+		case *Color:
+			fn(nil, entry)
+		case *Group:
+			entry.eachColor(fn)
 		}
 	}
-	return nil
 }
@@ -2,6 +2,10 @@ package ase
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"testing"
 )
 
@@ -90,6 +94,19 @@ func TestVersion(t *testing.T) {
 	}
 }
 
+func TestNumBlocks(t *testing.T) {
+	testFile := "samples/test.ase"
+
+	ase, err := DecodeFile(testFile)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if ase.NumBlocks() != 10 {
+		t.Error("expected 10 blocks, got:", ase.NumBlocks())
+	}
+}
+
 func TestDecode(t *testing.T) {
 	testFile := "samples/test.ase"
 
@@ -202,7 +219,7 @@ func TestEncode(t *testing.T) {
 		t.Error("ase: file not an ASE file")
 	}
 
-	if ase.version[0] != 1 && ase.version[1] != 0 {
+	if ase.FileVersion != Version1 {
 		t.Error("ase: version is not 1.0")
 	}
 
@@ -285,3 +302,432 @@ func TestEncode(t *testing.T) {
 	}
 
 }
+
+func TestEncodeEntriesOrder(t *testing.T) {
+	group := testGroup
+	loose := testColors[0]
+
+	// A loose color following a group can't be expressed through Colors and Groups alone.
+	sampleAse := ASE{}
+	sampleAse.Entries = []Entry{&group, &loose}
+
+	b := new(bytes.Buffer)
+	if err := Encode(sampleAse, b); err != nil {
+		t.Fatal(err)
+	}
+
+	ase, err := Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ase.Entries) != 2 {
+		t.Fatal("expected 2 entries, got", len(ase.Entries))
+	}
+	if g, ok := ase.Entries[0].(*Group); !ok || g.Name != testGroup.Name {
+		t.Errorf("expected first entry to be group %q, got %#v", testGroup.Name, ase.Entries[0])
+	}
+	if c, ok := ase.Entries[1].(*Color); !ok || c.Name != loose.Name {
+		t.Errorf("expected second entry to be color %q, got %#v", loose.Name, ase.Entries[1])
+	}
+
+	// The legacy views are still populated.
+	if len(ase.Colors) != 1 || len(ase.Groups) != 1 {
+		t.Error("expected 1 color and 1 group, got", len(ase.Colors), "and", len(ase.Groups))
+	}
+
+	// Entries share storage with Colors and Groups.
+	ase.Colors[0].Name = "Renamed"
+	if ase.Entries[1].(*Color).Name != "Renamed" {
+		t.Error("expected entries to reference the Colors slice")
+	}
+}
+
+func TestEncodeEntries(t *testing.T) {
+	extra := Color{Name: "Extra", Model: RGB, Values: []float32{0.5, 0.5, 0.5}, Type: Normal}
+
+	tests := []struct {
+		name string
+		edit func(ase *ASE)
+		want []string
+	}{
+		{
+			name: "prepend",
+			edit: func(ase *ASE) { ase.Entries = append([]Entry{&extra}, ase.Entries...) },
+			want: []string{"Extra", "RGB", "Grayscale", "A Color Group/", "cmyk", "LAB", "PANTONE P 1-8 C"},
+		},
+		{
+			name: "delete",
+			edit: func(ase *ASE) { ase.Entries = ase.Entries[1:] },
+			want: []string{"Grayscale", "A Color Group/", "cmyk", "LAB", "PANTONE P 1-8 C"},
+		},
+		{
+			// Entries is what gets written, Colors and Groups are only
+			// views of it.
+			name: "prepend to Colors",
+			edit: func(ase *ASE) { ase.Colors = append([]Color{extra}, ase.Colors...) },
+			want: []string{"RGB", "Grayscale", "A Color Group/", "cmyk", "LAB", "PANTONE P 1-8 C"},
+		},
+		{
+			name: "mixed",
+			edit: func(ase *ASE) {
+				c := extra
+				*ase = ASE{Colors: []Color{c}, Entries: []Entry{&c}}
+			},
+			want: []string{"Extra"},
+		},
+	}
+
+	for _, test := range tests {
+		ase, err := DecodeFile("samples/test.ase")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Move the group between the loose colors, where Colors followed by
+		// Groups can't put it.
+		ase.Entries = []Entry{ase.Entries[0], ase.Entries[1], ase.Entries[5], ase.Entries[2], ase.Entries[3], ase.Entries[4]}
+		test.edit(&ase)
+
+		b := new(bytes.Buffer)
+		if err = Encode(ase, b); err != nil {
+			t.Fatal(test.name, err)
+		}
+		decoded, err := Decode(b)
+		if err != nil {
+			t.Fatal(test.name, err)
+		}
+
+		var names []string
+		for _, entry := range decoded.Entries {
+			switch entry := entry.(type) {
+			case *Color:
+				names = append(names, entry.Name)
+			case *Group:
+				names = append(names, entry.Name+"/")
+			}
+		}
+		if fmt.Sprint(names) != fmt.Sprint(test.want) {
+			t.Errorf("%s: expected %q, got %q", test.name, test.want, names)
+		}
+	}
+}
+
+func TestEncodeGroupEntries(t *testing.T) {
+	extra := Color{Name: "Extra", Model: RGB, Values: []float32{0.5, 0.5, 0.5}, Type: Normal}
+
+	tests := []struct {
+		name string
+		edit func(group *Group)
+		want []string
+	}{
+		{
+			name: "prepend",
+			edit: func(group *Group) { group.Entries = append([]Entry{&extra}, group.Entries...) },
+			want: []string{"Extra", "Red", "Green", "Blue"},
+		},
+		{
+			name: "delete",
+			edit: func(group *Group) { group.Entries = group.Entries[1:] },
+			want: []string{"Green", "Blue"},
+		},
+		{
+			// Entries is what gets written, Colors is only a view of it.
+			name: "append to Colors",
+			edit: func(group *Group) { group.Colors = append(group.Colors, extra) },
+			want: []string{"Red", "Green", "Blue"},
+		},
+		{
+			name: "mixed",
+			edit: func(group *Group) {
+				c := extra
+				*group = Group{Name: group.Name, Colors: []Color{c}, Entries: []Entry{&c}}
+			},
+			want: []string{"Extra"},
+		},
+	}
+
+	for _, test := range tests {
+		ase, err := DecodeFile("samples/test.ase")
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.edit(&ase.Groups[0])
+
+		b := new(bytes.Buffer)
+		if err = Encode(ase, b); err != nil {
+			t.Fatal(test.name, err)
+		}
+		decoded, err := Decode(b)
+		if err != nil {
+			t.Fatal(test.name, err)
+		}
+
+		var names []string
+		for _, entry := range decoded.Groups[0].Entries {
+			names = append(names, entry.(*Color).Name)
+		}
+		if fmt.Sprint(names) != fmt.Sprint(test.want) {
+			t.Errorf("%s: expected %q, got %q", test.name, test.want, names)
+		}
+	}
+}
+
+func TestRoundTripBytes(t *testing.T) {
+	for _, file := range []string{"samples/test.ase", "samples/test-2.ase"} {
+		in, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ase, err := Decode(bytes.NewReader(in))
+		if err != nil {
+			t.Fatal(file, err)
+		}
+
+		out := new(bytes.Buffer)
+		if err = Encode(ase, out); err != nil {
+			t.Fatal(file, err)
+		}
+
+		if !bytes.Equal(in, out.Bytes()) {
+			t.Error(file, "did not round-trip byte for byte")
+		}
+	}
+}
+
+func TestDecodeLenient(t *testing.T) {
+	in, err := ioutil.ReadFile("samples/test.ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Splice a vendor block in front of the group start and another one
+	// inside the group, right after its name, and bump the block count.
+	vendor := []byte{0xbe, 0xef, 0, 0, 0, 3, 'x', 'y', 'z'}
+	groupAt := bytes.Index(in, []byte{0xc0, 0x01})
+	firstColorInGroup := groupAt + 6 + 0x1e
+
+	var data []byte
+	data = append(data, in[:groupAt]...)
+	data = append(data, vendor...)
+	data = append(data, in[groupAt:firstColorInGroup]...)
+	data = append(data, vendor...)
+	data = append(data, in[firstColorInGroup:]...)
+	data[11] += 2
+
+	if _, err = Decode(bytes.NewReader(data)); err != ErrInvalidBlockType {
+		t.Fatal("expected", ErrInvalidBlockType, "got", err)
+	}
+
+	ase, err := DecodeWithOptions(bytes.NewReader(data), DecoderOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ase.Colors) != 5 || len(ase.Groups) != 1 || len(ase.Groups[0].Colors) != 3 {
+		t.Error("expected vendor blocks to be skipped")
+	}
+
+	raw, ok := ase.Entries[5].(*RawBlock)
+	if !ok || raw.Type != 0xbeef || string(raw.Data) != "xyz" {
+		t.Errorf("expected raw block entry, got %#v", ase.Entries[5])
+	}
+
+	out := new(bytes.Buffer)
+	if err = Encode(ase, out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, out.Bytes()) {
+		t.Error("raw blocks were not written back unchanged")
+	}
+}
+
+func TestDecodeBlockLength(t *testing.T) {
+	in, err := ioutil.ReadFile("samples/test.ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first color block's length sits at offset 14 and its payload starts
+	// at 18 with the name length. Claiming a longer name runs the color past
+	// the end of its block.
+	truncated := append([]byte{}, in...)
+	truncated[19] = 0x40
+
+	// Growing the block by two bytes without touching its contents leaves
+	// data behind.
+	trailing := append(append(append([]byte{}, in[:17]...), 0x1e), in[18:46]...)
+	trailing = append(append(trailing, 0, 0), in[46:]...)
+
+	tests := []struct {
+		data []byte
+		want error
+	}{
+		{truncated, ErrBlockTruncated},
+		{trailing, ErrBlockTrailing},
+	}
+
+	for _, test := range tests {
+		_, err := Decode(bytes.NewReader(test.data))
+		blockErr, ok := err.(*BlockError)
+		if !ok || blockErr.Err != test.want || blockErr.Index != 0 {
+			t.Errorf("expected %v in block 0, got %v", test.want, err)
+		}
+
+		ase, err := DecodeWithOptions(bytes.NewReader(test.data), DecoderOptions{Lenient: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The broken color is kept as raw, everything after it decodes fine.
+		raw, ok := ase.Entries[0].(*RawBlock)
+		if !ok || !errors.Is(raw.Err, test.want) {
+			t.Errorf("expected raw block with %v, got %#v", test.want, ase.Entries[0])
+		}
+		if len(ase.Colors) != 4 || ase.Colors[0].Name != "Grayscale" || len(ase.Groups) != 1 {
+			t.Errorf("expected decoding to resume at the next block, got %+v", ase)
+		}
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	in, err := ioutil.ReadFile("samples/test.ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cut the file in the middle of the group's start block.
+	groupAt := bytes.Index(in, []byte{0xc0, 0x01})
+	ase, err := Decode(bytes.NewReader(in[:groupAt+10]))
+	if err != io.ErrUnexpectedEOF {
+		t.Fatal("expected", io.ErrUnexpectedEOF, "got", err)
+	}
+
+	// The loose colors read before the error are kept, without an order.
+	if len(ase.Colors) != 5 || len(ase.Groups) != 0 || len(ase.Entries) != 0 {
+		t.Errorf("unexpected views: %d colors, %d groups, %d entries", len(ase.Colors), len(ase.Groups), len(ase.Entries))
+	}
+}
+
+// Returns samples/test.ase with the group's start and end blocks grown by two
+// bytes each, leaving data behind in both.
+func malformedGroupFile(t *testing.T) []byte {
+	in, err := ioutil.ReadFile("samples/test.ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	startAt := bytes.Index(in, []byte{0xc0, 0x01})
+	endAt := bytes.LastIndex(in, []byte{0xc0, 0x02})
+
+	var data []byte
+	data = append(data, in[:startAt+6]...)
+	data = append(data, in[startAt+6:startAt+6+0x1e]...)
+	data = append(data, 0, 0)
+	data = append(data, in[startAt+6+0x1e:endAt+6]...)
+	data = append(data, 0, 0)
+	data = append(data, in[endAt+6:]...)
+
+	// The low bytes of the lengths, the end block having moved by two.
+	data[startAt+5] += 2
+	data[endAt+2+5] += 2
+
+	return data
+}
+
+func TestDecodeMalformedGroup(t *testing.T) {
+	data := malformedGroupFile(t)
+
+	if _, err := Decode(bytes.NewReader(data)); !errors.Is(err, ErrBlockTrailing) {
+		t.Fatal("expected", ErrBlockTrailing, "got", err)
+	}
+
+	ase, err := DecodeWithOptions(bytes.NewReader(data), DecoderOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ase.Groups) != 1 || len(ase.Groups[0].Colors) != 3 {
+		t.Fatalf("expected the group to be decoded, got %+v", ase.Groups)
+	}
+	group := ase.Groups[0]
+	if group.Name != "A Color Group" {
+		t.Error("expected the group to keep its name, got", group.Name)
+	}
+	for _, raw := range []*RawBlock{group.RawStart, group.RawEnd} {
+		if raw == nil || !errors.Is(raw.Err, ErrBlockTrailing) {
+			t.Errorf("expected a raw block with %v, got %#v", ErrBlockTrailing, raw)
+		}
+	}
+
+	out := new(bytes.Buffer)
+	if err = Encode(ase, out); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, out.Bytes()) {
+		t.Error("malformed group blocks were not written back unchanged")
+	}
+}
+
+func TestVersionPolicy(t *testing.T) {
+	in, err := ioutil.ReadFile("samples/test.ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withVersion := func(major, minor byte) []byte {
+		data := append([]byte{}, in...)
+		data[5], data[7] = major, minor
+		return data
+	}
+
+	tests := []struct {
+		data   []byte
+		policy VersionPolicy
+		ok     bool
+	}{
+		{withVersion(1, 0), VersionStrict, true},
+		{withVersion(1, 1), VersionStrict, false},
+		{withVersion(2, 0), VersionStrict, false},
+		{withVersion(1, 1), VersionMajor, true},
+		{withVersion(2, 0), VersionMajor, false},
+		{withVersion(3, 7), VersionAny, true},
+	}
+
+	for _, test := range tests {
+		ase, err := DecodeWithOptions(bytes.NewReader(test.data), DecoderOptions{Version: test.policy})
+		if test.ok && err != nil {
+			t.Error(test.policy, ase.FileVersion, err)
+		}
+		if !test.ok && err != ErrInvalidVersion {
+			t.Error("expected", ErrInvalidVersion, "got", err)
+		}
+	}
+
+	// The decoded version is kept when encoding, unless overridden.
+	ase, err := DecodeWithOptions(bytes.NewReader(withVersion(1, 2)), DecoderOptions{Version: VersionMajor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ase.Version() != "1.2" {
+		t.Error("expected version 1.2, got", ase.Version())
+	}
+
+	b := new(bytes.Buffer)
+	if err = Encode(ase, b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), withVersion(1, 2)) {
+		t.Error("expected version 1.2 to be written back")
+	}
+
+	b.Reset()
+	if err = EncodeWithOptions(ase, b, EncoderOptions{Version: Version1}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), in) {
+		t.Error("expected version 1.0 to be written")
+	}
+}
@@ -1,7 +1,9 @@
 package ase
 
 import (
+	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
 )
 
@@ -35,3 +37,82 @@ func (block *block) readType(r io.Reader) error {
 func (block *block) readLength(r io.Reader) error {
 	return binary.Read(r, binary.BigEndian, &block.Length)
 }
+
+// Reads the block's payload, as many bytes as its declared length.
+func (b *block) readPayload(r io.Reader) (payload []byte, err error) {
+	if b.Length < 0 {
+		return nil, ErrInvalidBlockLen
+	}
+
+	//	copy rather than allocating Length bytes up front, a corrupt
+	//	length shouldn't be able to exhaust memory
+	buf := new(bytes.Buffer)
+	if _, err = io.CopyN(buf, r, int64(b.Length)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+
+	return buf.Bytes(), nil
+}
+
+// A BlockError reports a block whose payload doesn't match its declared length
+// or couldn't be decoded.
+type BlockError struct {
+	Index  int    // position of the block in the file, starting at 0
+	Type   uint16 // the block's type
+	Length int32  // the block's declared length
+	Err    error
+}
+
+func (e *BlockError) Error() string {
+	return fmt.Sprintf("ase: block %d (type 0x%04x, length %d): %v", e.Index, e.Type, e.Length, e.Err)
+}
+
+func (e *BlockError) Unwrap() error {
+	return e.Err
+}
+
+// Wraps the outcome of decoding a block's payload from rest. Running out of
+// payload is reported as ErrBlockTruncated and leftover payload as
+// ErrBlockTrailing.
+func blockError(index int, b block, rest *bytes.Reader, err error) error {
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		err = ErrBlockTruncated
+	case err == nil && rest.Len() > 0:
+		err = ErrBlockTrailing
+	case err == nil:
+		return nil
+	}
+
+	return &BlockError{Index: index, Type: b.Type, Length: b.Length, Err: err}
+}
+
+// RawBlock is a block of a type the decoder doesn't know about. It is only
+// produced when decoding leniently and is written back to the output as is.
+type RawBlock struct {
+	Type uint16
+	Data []byte
+
+	// Err is set when the block has a known type but a malformed payload.
+	Err error
+}
+
+// Encodes the block's type, length and payload.
+func (raw *RawBlock) write(w io.Writer) (err error) {
+	if err = binary.Write(w, binary.BigEndian, raw.Type); err != nil {
+		return
+	}
+	if err = binary.Write(w, binary.BigEndian, int32(len(raw.Data))); err != nil {
+		return
+	}
+	_, err = w.Write(raw.Data)
+	return
+}
+
+// A raw block is always a single block.
+func (raw *RawBlock) blockCount() int32 {
+	return 1
+}
@@ -0,0 +1,534 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ARolek/ase"
+)
+
+// Returns a flag set for the named command that reports errors to stderr.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("ase "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: ase %s %s\n", name, commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// Parses args into fs and checks the number of arguments left is between min
+// and max, max < 0 meaning any.
+func parseArgs(fs *flag.FlagSet, args []string, min, max int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if n := fs.NArg(); n < min || (max >= 0 && n > max) {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+func runInfo(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("info", stderr)
+	from := fs.String("from", "", "input format")
+	if err := parseArgs(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	palette, err := readPalette(fs.Arg(0), *from, ase.DecoderOptions{Lenient: true, Version: ase.VersionAny})
+	if err != nil {
+		return err
+	}
+
+	var colors, raws int
+	models := map[string]int{}
+	types := map[string]int{}
+	for _, c := range palette.Palette() {
+		c := c.(ase.Color)
+		colors++
+		models[c.Model.String()]++
+		types[c.Type.String()]++
+	}
+	for _, entry := range palette.Entries {
+		switch entry := entry.(type) {
+		case *ase.RawBlock:
+			raws++
+		case *ase.Group:
+			for _, e := range entry.Entries {
+				if _, ok := e.(*ase.RawBlock); ok {
+					raws++
+				}
+			}
+		}
+	}
+
+	//	the count the header declares, or the one Encode would write for
+	//	other formats
+	blocks := int(palette.NumBlocks())
+	if blocks == 0 {
+		blocks = colors + 2*len(palette.Groups) + raws
+	}
+
+	fmt.Fprintf(stdout, "version:  %s\n", palette.FileVersion.String())
+	fmt.Fprintf(stdout, "blocks:   %d\n", blocks)
+	fmt.Fprintf(stdout, "colors:   %d (%d loose)\n", colors, len(palette.Colors))
+	fmt.Fprintf(stdout, "models:   %s\n", counts(models, "RGB", "CMYK", "LAB", "Gray"))
+	fmt.Fprintf(stdout, "types:    %s\n", counts(types, "Global", "Spot", "Normal"))
+	if raws > 0 {
+		fmt.Fprintf(stdout, "unknown:  %d blocks\n", raws)
+	}
+	fmt.Fprintf(stdout, "groups:   %d\n", len(palette.Groups))
+	for _, g := range palette.Groups {
+		fmt.Fprintf(stdout, "  %-30s %d colors\n", g.Name, len(g.Colors))
+	}
+
+	return nil
+}
+
+// Formats the non zero counts of keys, in order.
+func counts(m map[string]int, keys ...string) string {
+	var parts []string
+	for _, k := range keys {
+		if m[k] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", k, m[k]))
+		}
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func runDump(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("dump", stderr)
+	from := fs.String("from", "", "input format")
+	asJSON := fs.Bool("json", false, "print JSON instead of a tree")
+	if err := parseArgs(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	palette, err := readPalette(fs.Arg(0), *from, ase.DecoderOptions{Lenient: true, Version: ase.VersionAny})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(palette, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(stdout, "%s\n", out)
+		return err
+	}
+
+	fmt.Fprintf(stdout, "ASE %s\n", palette.FileVersion.String())
+	entries := palette.Entries
+	if len(entries) == 0 {
+		for i := range palette.Colors {
+			entries = append(entries, &palette.Colors[i])
+		}
+		for i := range palette.Groups {
+			entries = append(entries, &palette.Groups[i])
+		}
+	}
+
+	for i, entry := range entries {
+		last := i == len(entries)-1
+		branch, indent := "├── ", "│   "
+		if last {
+			branch, indent = "└── ", "    "
+		}
+
+		switch entry := entry.(type) {
+		case *ase.Group:
+			fmt.Fprintf(stdout, "%s%s/\n", branch, entry.Name)
+			groupEntries := entry.Entries
+			if len(groupEntries) == 0 {
+				for j := range entry.Colors {
+					groupEntries = append(groupEntries, &entry.Colors[j])
+				}
+			}
+			for j, e := range groupEntries {
+				sub := "├── "
+				if j == len(groupEntries)-1 {
+					sub = "└── "
+				}
+				fmt.Fprintln(stdout, indent+sub+describeEntry(e))
+			}
+		default:
+			fmt.Fprintln(stdout, branch+describeEntry(entry))
+		}
+	}
+
+	return nil
+}
+
+// Describes a color or a raw block on one line.
+func describeEntry(entry ase.Entry) string {
+	switch entry := entry.(type) {
+	case *ase.Color:
+		return describe(entry)
+	case *ase.RawBlock:
+		return fmt.Sprintf("block 0x%04x, %d bytes", entry.Type, len(entry.Data))
+	}
+	return ""
+}
+
+// Describes a color on one line: name, type, model, values and hex code.
+func describe(c *ase.Color) string {
+	values := make([]string, len(c.Values))
+	for i, v := range c.Values {
+		values[i] = fmt.Sprint(v)
+	}
+
+	s := fmt.Sprintf("%s (%s) %s %s", c.Name, c.Type, c.Model, strings.Join(values, " "))
+	if hex, err := c.Hex(); err == nil {
+		s += " " + hex
+	}
+	return s
+}
+
+func runConvert(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("convert", stderr)
+	from := fs.String("from", "", "input format: ase, aco, gpl, json or tokens (default from the file name)")
+	to := fs.String("to", "", "output format: ase, aco, gpl, json or tokens (default from the file name)")
+	lenient := fs.Bool("lenient", false, "keep unknown and malformed ASE blocks instead of failing")
+	if err := parseArgs(fs, args, 2, 2); err != nil {
+		return err
+	}
+
+	palette, err := readPalette(fs.Arg(0), *from, ase.DecoderOptions{Lenient: *lenient, Version: ase.VersionAny})
+	if err != nil {
+		return err
+	}
+
+	return writePalette(palette, fs.Arg(1), *to, stdout)
+}
+
+func runValidate(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("validate", stderr)
+	from := fs.String("from", "", "input format")
+	lenient := fs.Bool("lenient", false, "report malformed ASE blocks and keep going")
+	if err := parseArgs(fs, args, 1, -1); err != nil {
+		return err
+	}
+
+	failed := false
+	for _, path := range fs.Args() {
+		palette, err := readPalette(path, *from, ase.DecoderOptions{Lenient: *lenient})
+		if err != nil {
+			fmt.Fprintf(stdout, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+
+		var problems []string
+
+		//	blocks lenient decoding had to skip
+		for i, entry := range palette.Entries {
+			if raw, ok := entry.(*ase.RawBlock); ok && raw.Err != nil {
+				problems = append(problems, fmt.Sprintf("Entries[%d]: %v", i, raw.Err))
+			}
+		}
+		for i, group := range palette.Groups {
+			if group.RawStart != nil {
+				problems = append(problems, fmt.Sprintf("Groups[%d].RawStart: %v", i, group.RawStart.Err))
+			}
+			for j, entry := range group.Entries {
+				if raw, ok := entry.(*ase.RawBlock); ok && raw.Err != nil {
+					problems = append(problems, fmt.Sprintf("Groups[%d].Entries[%d]: %v", i, j, raw.Err))
+				}
+			}
+			if group.RawEnd != nil {
+				problems = append(problems, fmt.Sprintf("Groups[%d].RawEnd: %v", i, group.RawEnd.Err))
+			}
+		}
+
+		var list ase.ErrorList
+		if err := palette.Validate(); errors.As(err, &list) {
+			for _, e := range list {
+				problems = append(problems, e.Error())
+			}
+		}
+
+		if len(problems) == 0 {
+			fmt.Fprintf(stdout, "%s: ok\n", path)
+			continue
+		}
+		failed = true
+		for _, p := range problems {
+			fmt.Fprintf(stdout, "%s: %s\n", path, p)
+		}
+	}
+
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+func runDiff(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("diff", stderr)
+	from := fs.String("from", "", "input format")
+	asJSON := fs.Bool("json", false, "print JSON instead of text")
+	if err := parseArgs(fs, args, 2, 2); err != nil {
+		return err
+	}
+
+	opts := ase.DecoderOptions{Lenient: true, Version: ase.VersionAny}
+	a, err := readPalette(fs.Arg(0), *from, opts)
+	if err != nil {
+		return err
+	}
+	b, err := readPalette(fs.Arg(1), *from, opts)
+	if err != nil {
+		return err
+	}
+
+	changes := ase.Diff(a, b)
+
+	if *asJSON {
+		if changes == nil {
+			changes = []ase.Change{}
+		}
+		out, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s\n", out)
+	} else {
+		for _, change := range changes {
+			fmt.Fprintln(stdout, change)
+		}
+	}
+
+	//	like diff(1), differences make for exit code 1 and errors for 2
+	if len(changes) > 0 {
+		return errFailed
+	}
+	return nil
+}
+
+func runExtract(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("extract", stderr)
+	n := fs.Int("n", 8, "number of colors")
+	method := fs.String("method", "median", "median for median cut, or kmeans")
+	hex := fs.Bool("hex", false, "name colors after their hex code instead of their rank")
+	transparent := fs.Bool("transparent", false, "count transparent pixels too")
+	name := fs.String("name", "", "group name (default the image's file name)")
+	colorType := fs.String("type", "Normal", "color type: Global, Spot or Normal")
+	to := fs.String("to", "", "output format (default from the file name)")
+	if err := parseArgs(fs, args, 2, 2); err != nil {
+		return err
+	}
+
+	opts := ase.ExtractOptions{Colors: *n, HexNames: *hex, IncludeTransparent: *transparent, Name: *name}
+	switch *method {
+	case "median":
+		opts.Method = ase.MedianCut
+	case "kmeans":
+		opts.Method = ase.KMeans
+	default:
+		return fmt.Errorf("unknown method %q", *method)
+	}
+	if *n <= 0 {
+		return fmt.Errorf("can't extract %d colors", *n)
+	}
+
+	var err error
+	if opts.Type, err = ase.ParseColorType(*colorType); err != nil {
+		return err
+	}
+	if opts.Name == "" && fs.Arg(0) != "-" {
+		base := filepath.Base(fs.Arg(0))
+		opts.Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	img, err := readImage(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	group, err := ase.Extract(img, opts)
+	if err != nil {
+		return err
+	}
+
+	return writePalette(ase.ASE{Groups: []ase.Group{group}}, fs.Arg(1), *to, stdout)
+}
+
+func runRecolor(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("recolor", stderr)
+	from := fs.String("from", "", "palette format")
+	dither := fs.Bool("dither", false, "use Floyd-Steinberg dithering")
+	if err := parseArgs(fs, args, 3, 3); err != nil {
+		return err
+	}
+
+	palette, err := readPalette(fs.Arg(0), *from, ase.DecoderOptions{Lenient: true, Version: ase.VersionAny})
+	if err != nil {
+		return err
+	}
+	img, err := readImage(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	recolored, err := ase.Recolor(img, palette, ase.RecolorOptions{Dither: *dither})
+	if err != nil {
+		return err
+	}
+
+	return writePNG(recolored, fs.Arg(2), stdout)
+}
+
+func runPreview(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("preview", stderr)
+	from := fs.String("from", "", "palette format")
+	format := fs.String("format", "", "png or svg (default from the file name, png for stdout)")
+	columns := fs.Int("columns", 8, "most swatches per row")
+	scale := fs.Int("scale", 1, "size multiplier")
+	if err := parseArgs(fs, args, 2, 2); err != nil {
+		return err
+	}
+
+	palette, err := readPalette(fs.Arg(0), *from, ase.DecoderOptions{Lenient: true, Version: ase.VersionAny})
+	if err != nil {
+		return err
+	}
+
+	out := fs.Arg(1)
+	if *format == "" {
+		*format = "png"
+		if strings.EqualFold(filepath.Ext(out), ".svg") {
+			*format = "svg"
+		}
+	}
+
+	opts := ase.RenderOptions{Columns: *columns, Scale: *scale}
+	var b bytes.Buffer
+	switch *format {
+	case "png":
+		err = ase.RenderPNG(palette, &b, opts)
+	case "svg":
+		err = ase.RenderSVG(palette, &b, opts)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeFile(b.Bytes(), out, stdout)
+}
+
+func runHTML(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("html", stderr)
+	from := fs.String("from", "", "palette format")
+	title := fs.String("title", "", "page title (default the palette's file name)")
+	if err := parseArgs(fs, args, 2, 2); err != nil {
+		return err
+	}
+
+	palette, err := readPalette(fs.Arg(0), *from, ase.DecoderOptions{Lenient: true, Version: ase.VersionAny})
+	if err != nil {
+		return err
+	}
+
+	opts := ase.HTMLOptions{Title: *title}
+	if opts.Title == "" && fs.Arg(0) != "-" {
+		opts.Title = filepath.Base(fs.Arg(0))
+	}
+
+	var b bytes.Buffer
+	if err = ase.RenderHTML(palette, &b, opts); err != nil {
+		return err
+	}
+
+	return writeFile(b.Bytes(), fs.Arg(1), stdout)
+}
+
+func runContrast(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("contrast", stderr)
+	from := fs.String("from", "", "palette format")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	text := fs.String("text", "", "comma separated groups of text colors, - for loose colors (default all)")
+	background := fs.String("background", "", "comma separated groups of background colors, - for loose colors (default all)")
+	failing := fs.Bool("failing", false, "only list pairs failing WCAG AA for normal text")
+	if err := parseArgs(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	palette, err := readPalette(fs.Arg(0), *from, ase.DecoderOptions{Lenient: true, Version: ase.VersionAny})
+	if err != nil {
+		return err
+	}
+
+	pairs := ase.ContrastMatrix(palette, ase.ContrastOptions{
+		TextGroups:       groupList(*text),
+		BackgroundGroups: groupList(*background),
+	})
+	if *failing {
+		kept := pairs[:0]
+		for _, p := range pairs {
+			if !p.AA {
+				kept = append(kept, p)
+			}
+		}
+		pairs = kept
+	}
+
+	if *asJSON {
+		if pairs == nil {
+			pairs = []ase.ContrastPair{}
+		}
+		out, err := json.MarshalIndent(pairs, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(stdout, "%s\n", out)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TEXT\tBACKGROUND\tRATIO\tWCAG\tAPCA Lc")
+	for _, p := range pairs {
+		grade := "fail"
+		switch {
+		case p.AAA:
+			grade = "AAA"
+		case p.AA:
+			grade = "AA"
+		case p.AALarge:
+			grade = "AA large"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.2f\t%s\t%.1f\n",
+			ase.ColorPath(p.TextGroup, p.Text.Name), ase.ColorPath(p.BackgroundGroup, p.Background.Name), p.Ratio, grade, p.Lc)
+	}
+
+	return tw.Flush()
+}
+
+// Splits a comma separated list of group names, "-" standing for loose
+// colors.
+func groupList(s string) (groups []string) {
+	if s == "" {
+		return nil
+	}
+	for _, g := range strings.Split(s, ",") {
+		if g = strings.TrimSpace(g); g == "-" {
+			g = ""
+		}
+		groups = append(groups, g)
+	}
+	return
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ARolek/ase"
+)
+
+// The file formats the tool reads and writes.
+const (
+	formatASE    = "ase"
+	formatACO    = "aco"
+	formatGPL    = "gpl"
+	formatJSON   = "json"
+	formatTokens = "tokens"
+)
+
+// Guesses a file's format from its name. Design tokens files end in
+// .tokens.json or .tokens.
+func formatOf(path string) (string, error) {
+	name := strings.ToLower(filepath.Base(path))
+	if strings.HasSuffix(name, ".tokens.json") || strings.HasSuffix(name, ".tokens") {
+		return formatTokens, nil
+	}
+
+	switch ext := strings.TrimPrefix(filepath.Ext(name), "."); ext {
+	case formatASE, formatACO, formatGPL, formatJSON:
+		return ext, nil
+	}
+
+	return "", fmt.Errorf("can't tell the format of %q, use -from or -to", path)
+}
+
+// Reads the palette at path, "-" for stdin, in the given format or the one
+// its name suggests.
+func readPalette(path, format string, opts ase.DecoderOptions) (palette ase.ASE, err error) {
+	if format == "" {
+		if format, err = formatOf(path); err != nil {
+			return
+		}
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return palette, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	switch format {
+	case formatASE:
+		return ase.DecodeWithOptions(r, opts)
+	case formatACO:
+		return ase.DecodeACO(r)
+	case formatGPL:
+		palette, _, err = ase.DecodeGPL(r)
+		return
+	case formatJSON:
+		err = json.NewDecoder(r).Decode(&palette)
+		return
+	case formatTokens:
+		return ase.DecodeTokens(r)
+	}
+
+	return palette, fmt.Errorf("unknown format %q", format)
+}
+
+// Writes palette to path, "-" for stdout, in the given format or the one its
+// name suggests. Nothing is written if encoding fails.
+func writePalette(palette ase.ASE, path, format string, stdout io.Writer) (err error) {
+	if format == "" {
+		if format, err = formatOf(path); err != nil {
+			return
+		}
+	}
+
+	var b bytes.Buffer
+	switch format {
+	case formatASE:
+		err = ase.Encode(palette, &b)
+	case formatACO:
+		err = ase.EncodeACO(palette, &b)
+	case formatGPL:
+		err = ase.EncodeGPL(palette, &b, ase.GPLInfo{})
+	case formatJSON:
+		var out []byte
+		if out, err = json.MarshalIndent(palette, "", "  "); err == nil {
+			b.Write(append(out, '\n'))
+		}
+	case formatTokens:
+		err = ase.EncodeTokens(palette, &b)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return
+	}
+
+	return writeFile(b.Bytes(), path, stdout)
+}
+
+// Reads the PNG, JPEG or GIF image at path, "-" for stdin.
+func readImage(path string) (img image.Image, err error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	img, _, err = image.Decode(r)
+	return
+}
+
+// Writes img as a PNG to path, "-" for stdout. Nothing is written if encoding
+// fails.
+func writePNG(img image.Image, path string, stdout io.Writer) (err error) {
+	var b bytes.Buffer
+	if err = png.Encode(&b, img); err != nil {
+		return
+	}
+
+	return writeFile(b.Bytes(), path, stdout)
+}
+
+// Writes data to path, "-" for stdout.
+func writeFile(data []byte, path string, stdout io.Writer) (err error) {
+	if path == "-" {
+		_, err = stdout.Write(data)
+		return
+	}
+	return os.WriteFile(path, data, 0666)
+}
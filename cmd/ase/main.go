@@ -0,0 +1,101 @@
+// Command ase inspects, validates and converts color swatch files.
+//
+// Usage:
+//
+//	ase <command> [flags] [arguments]
+//
+// Run "ase help" for the list of commands.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// A command is an ase subcommand.
+type command struct {
+	usage string // arguments, after the command name
+	short string // one line description
+	run   func(args []string, stdout, stderr io.Writer) error
+}
+
+// The commands by name. Filled in by init, as commands refer to the table
+// for their usage.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"info":     {"file", "print version, block counts and groups", runInfo},
+		"dump":     {"[-json] file", "print every group and color", runDump},
+		"convert":  {"[-from format] [-to format] in out", "convert between ase, aco, gpl, json and tokens", runConvert},
+		"validate": {"[-lenient] file...", "check files and report what is wrong where", runValidate},
+		"contrast": {"[-json] [-text groups] [-background groups] [-failing] palette", "check WCAG 2 and APCA contrast between colors", runContrast},
+		"diff":     {"[-json] old new", "list the colors added, removed or changed, exit code 1 if any", runDiff},
+		"html":     {"[-title title] palette out.html", "write a self-contained HTML catalog of every group and color", runHTML},
+		"preview":  {"[-columns n] [-scale n] [-format png|svg] palette out", "draw a swatch sheet of every group and color", runPreview},
+		"recolor":  {"[-dither] palette image out.png", "map every pixel of an image to its closest swatch", runRecolor},
+		"extract":  {"[-n colors] [-method median|kmeans] [-hex] [-transparent] image out", "pick the main colors of a PNG, JPEG or GIF image", runExtract},
+	}
+}
+
+// errFailed reports problems the command found and already told the user
+// about, such as differences or invalid colors.
+var errFailed = fmt.Errorf("failed")
+
+// errUsage reports bad arguments, the usage having been printed already.
+var errUsage = fmt.Errorf("usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// Runs the command line args and returns the exit code: like diff(1), 1 when
+// the command found problems and 2 when it couldn't do its job.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "-help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "ase: unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	if err := cmd.run(args[1:], stdout, stderr); err != nil {
+		if err == errFailed {
+			return 1
+		}
+		if err != errUsage {
+			fmt.Fprintf(stderr, "ase %s: %v\n", args[0], err)
+		}
+		return 2
+	}
+
+	return 0
+}
+
+// Prints the list of commands.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: ase <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-9s %s\n", name, commands[name].short)
+		fmt.Fprintf(w, "  %-9s   ase %s %s\n", "", name, commands[name].usage)
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testFile = "../../samples/test.ase"
+
+func TestInfo(t *testing.T) {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if code := run([]string{"info", testFile}, stdout, stderr); code != 0 {
+		t.Fatal("exit code", code, stderr.String())
+	}
+
+	for _, s := range []string{"version:  1.0", "blocks:   10", "colors:   8 (5 loose)", "A Color Group"} {
+		if !strings.Contains(stdout.String(), s) {
+			t.Errorf("expected %q in\n%s", s, stdout.String())
+		}
+	}
+}
+
+func TestRawBlocks(t *testing.T) {
+	in, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Splice a vendor block into the group, right after its name, and bump
+	// the block count.
+	at := bytes.Index(in, []byte{0xc0, 0x01}) + 6 + 0x1e
+	data := append(append([]byte{}, in[:at]...), 0xbe, 0xef, 0, 0, 0, 3, 'x', 'y', 'z')
+	data = append(data, in[at:]...)
+	data[11]++
+	path := filepath.Join(t.TempDir(), "vendor.ase")
+	os.WriteFile(path, data, 0666)
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if code := run([]string{"dump", path}, stdout, stderr); code != 0 {
+		t.Fatal("exit code", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "└── A Color Group/\n    ├── block 0xbeef, 3 bytes\n    ├── Red") {
+		t.Errorf("expected the block in the group, got\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"info", path}, stdout, stderr); code != 0 {
+		t.Fatal("exit code", code, stderr.String())
+	}
+	for _, s := range []string{"blocks:   11", "unknown:  1 blocks"} {
+		if !strings.Contains(stdout.String(), s) {
+			t.Errorf("expected %q in\n%s", s, stdout.String())
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	dir := t.TempDir()
+	formats := []string{"x.aco", "x.gpl", "x.json", "x.tokens.json"}
+
+	for _, name := range formats {
+		out := filepath.Join(dir, name)
+		back := filepath.Join(dir, name+".ase")
+
+		stderr := new(bytes.Buffer)
+		if code := run([]string{"convert", testFile, out}, nil, stderr); code != 0 {
+			t.Fatal(name, "exit code", code, stderr.String())
+		}
+		if code := run([]string{"convert", out, back}, nil, stderr); code != 0 {
+			t.Fatal(name, "exit code", code, stderr.String())
+		}
+
+		stdout := new(bytes.Buffer)
+		if code := run([]string{"dump", back}, stdout, stderr); code != 0 {
+			t.Fatal(name, "exit code", code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), "Blue") {
+			t.Errorf("%s: expected Blue to survive, got\n%s", name, stdout.String())
+		}
+	}
+
+	// JSON goes through unchanged.
+	stdout := new(bytes.Buffer)
+	run([]string{"convert", "-to", "ase", filepath.Join(dir, "x.json"), "-"}, stdout, nil)
+	expected, _ := os.ReadFile(testFile)
+	if !bytes.Equal(stdout.Bytes(), expected) {
+		t.Error("expected JSON to convert back to the same file")
+	}
+
+	// Versions other than 1.0 convert, as they show in info and dump.
+	v2 := append([]byte{}, expected...)
+	v2[5] = 2
+	os.WriteFile(filepath.Join(dir, "v2.ase"), v2, 0666)
+	stdout.Reset()
+	stderr := new(bytes.Buffer)
+	if code := run([]string{"convert", "-to", "json", filepath.Join(dir, "v2.ase"), "-"}, stdout, stderr); code != 0 {
+		t.Fatal("exit code", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"version": "2.0"`) {
+		t.Error("expected version 2.0 to be kept, got", stdout.String())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte(`{"entries":[{"name":"A","colors":[{"name":"x","model":"RGB","values":[2,0],"type":"Spot"}]}]}`), 0666)
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if code := run([]string{"validate", testFile, bad}, stdout, stderr); code != 1 {
+		t.Error("expected exit code 1, got", code)
+	}
+
+	expected := testFile + ": ok\n" + bad + ": Groups[0].Colors[0].Values: ase: wrong number of values for color model: RGB takes 3, got 2\n"
+	if stdout.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, stdout.String())
+	}
+}
+
+func TestValidateLenient(t *testing.T) {
+	in, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Claim a longer name for the group's first color, running it past the
+	// end of its block.
+	groupAt := bytes.Index(in, []byte{0xc0, 0x01})
+	in[groupAt+6+0x1e+6] = 0x40
+	corrupt := filepath.Join(t.TempDir(), "corrupt.ase")
+	os.WriteFile(corrupt, in, 0666)
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if code := run([]string{"validate", corrupt}, stdout, stderr); code != 1 {
+		t.Error("expected exit code 1, got", code)
+	}
+	if !strings.Contains(stdout.String(), "block 6 (type 0x0001, length 28): ase: block is shorter than its contents") {
+		t.Error("unexpected output", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"validate", "-lenient", corrupt}, stdout, stderr); code != 1 {
+		t.Error("expected exit code 1, got", code)
+	}
+	expected := corrupt + ": Groups[0].Entries[0]: ase: block 6 (type 0x0001, length 28): ase: block is shorter than its contents\n"
+	if stdout.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, stdout.String())
+	}
+}
+
+func TestUsage(t *testing.T) {
+	stderr := new(bytes.Buffer)
+	if code := run([]string{"frobnicate"}, nil, stderr); code != 2 {
+		t.Error("expected exit code 2, got", code)
+	}
+	if !strings.Contains(stderr.String(), "unknown command") {
+		t.Error("unexpected output", stderr.String())
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	changed := filepath.Join(dir, "changed.json")
+	os.WriteFile(changed, []byte(`{"entries":[{"name":"RGB","model":"RGB","values":[1,1,1],"type":"Spot"}]}`), 0666)
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if code := run([]string{"diff", testFile, testFile}, stdout, stderr); code != 0 || stdout.Len() != 0 {
+		t.Error("expected no differences, got", code, stdout.String(), stderr.String())
+	}
+
+	if code := run([]string{"diff", testFile, changed}, stdout, stderr); code != 1 {
+		t.Error("expected exit code 1, got", code, stderr.String())
+	}
+
+	// Files that can't be read aren't differences.
+	errout := new(bytes.Buffer)
+	if code := run([]string{"diff", testFile, filepath.Join(dir, "missing.ase")}, new(bytes.Buffer), errout); code != 2 {
+		t.Error("expected exit code 2, got", code)
+	}
+	if !strings.Contains(errout.String(), "missing.ase") {
+		t.Error("expected the error on stderr, got", errout.String())
+	}
+	if code := run([]string{"diff", testFile}, new(bytes.Buffer), new(bytes.Buffer)); code != 2 {
+		t.Error("expected exit code 2 for a missing argument, got", code)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if lines[0] != "retyped RGB Normal -> Spot" || len(lines) != 8 {
+		t.Errorf("unexpected output\n%s", stdout.String())
+	}
+}
+
+func TestExtract(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	draw.Draw(img, image.Rect(0, 0, 4, 3), image.NewUniform(color.NRGBA{0xff, 0x80, 0, 0xff}), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 3, 4, 4), image.NewUniform(color.NRGBA{0, 0, 0x80, 0xff}), image.Point{}, draw.Src)
+
+	in := filepath.Join(dir, "logo.png")
+	f, _ := os.Create(in)
+	png.Encode(f, img)
+	f.Close()
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if code := run([]string{"extract", "-n", "4", "-hex", "-to", "gpl", in, "-"}, stdout, stderr); code != 0 {
+		t.Fatal("exit code", code, stderr.String())
+	}
+
+	expected := "GIMP Palette\nName: logo\n255 128   0\t#FF8000\n  0   0 128\t#000080\n"
+	if stdout.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, stdout.String())
+	}
+}
+
+func TestRecolor(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.Pix = []uint8{0x10, 0xf0}
+
+	in := filepath.Join(dir, "in.png")
+	f, _ := os.Create(in)
+	png.Encode(f, img)
+	f.Close()
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if code := run([]string{"recolor", testFile, in, "-"}, stdout, stderr); code != 0 {
+		t.Fatal("exit code", code, stderr.String())
+	}
+
+	out, err := png.Decode(stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paletted, ok := out.(*image.Paletted)
+	if !ok || len(paletted.Palette) != 8 {
+		t.Fatal("expected an 8 color paletted image, got", out)
+	}
+	if r, g, b, _ := out.At(1, 0).RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+		t.Error("expected white, got", r, g, b)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "sheet.svg")
+
+	stderr := new(bytes.Buffer)
+	if code := run([]string{"preview", testFile, out}, nil, stderr); code != 0 {
+		t.Fatal("exit code", code, stderr.String())
+	}
+	svg, _ := os.ReadFile(out)
+	if !strings.HasPrefix(string(svg), "<svg") || !strings.Contains(string(svg), ">A Color Group</text>") {
+		t.Error("unexpected SVG", string(svg))
+	}
+
+	stdout := new(bytes.Buffer)
+	if code := run([]string{"preview", "-columns", "3", testFile, "-"}, stdout, stderr); code != 0 {
+		t.Fatal("exit code", code, stderr.String())
+	}
+	img, err := png.Decode(stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 16+3*120+2*12+16 {
+		t.Error("expected 3 columns, got", img.Bounds())
+	}
+}
+
+func TestHTML(t *testing.T) {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if code := run([]string{"html", testFile, "-"}, stdout, stderr); code != 0 {
+		t.Fatal("exit code", code, stderr.String())
+	}
+
+	for _, s := range []string{"<title>test.ase</title>", "<h2>A Color Group</h2>", `data-copy="#FF0000"`} {
+		if !strings.Contains(stdout.String(), s) {
+			t.Errorf("expected %q in the page", s)
+		}
+	}
+}
+
+func TestContrast(t *testing.T) {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if code := run([]string{"contrast", "-text", "A Color Group", "-background", "-", testFile}, stdout, stderr); code != 0 {
+		t.Fatal("exit code", code, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 1+3*5 {
+		t.Fatalf("expected 15 pairs, got\n%s", stdout.String())
+	}
+	expected := "A Color Group/Red    RGB              4.00   AA large  64.1"
+	if lines[1] != expected {
+		t.Errorf("expected\n%q\ngot\n%q", expected, lines[1])
+	}
+
+	stdout.Reset()
+	run([]string{"contrast", "-json", "-failing", "-text", "-", "-background", "-", testFile}, stdout, stderr)
+	if !strings.HasPrefix(stdout.String(), "[\n  {\n    \"text\": {") || strings.Contains(stdout.String(), `"aa": true`) {
+		t.Errorf("unexpected JSON\n%s", stdout.String())
+	}
+}
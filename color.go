@@ -18,9 +18,136 @@ var (
 type Color struct {
 	nameLen uint16
 	Name    string
-	Model   string // CMYK, RGB, LAB or Gray
+	Model   ColorModel // CMYK, RGB, LAB or Gray
 	Values  []float32
-	Type    string // Global, Spot, Normal
+	Type    ColorType // Global, Spot, Normal
+}
+
+// ColorModel is the color space a color's Values are expressed in.
+//
+// Its underlying type is string, so untyped string constants such as "RGB"
+// still work where a ColorModel is expected. Use ParseColorModel to turn
+// arbitrary strings into one.
+type ColorModel string
+
+const (
+	CMYK ColorModel = "CMYK"
+	RGB  ColorModel = "RGB"
+	LAB  ColorModel = "LAB"
+	Gray ColorModel = "Gray"
+)
+
+// Returns the model's name, such as "RGB".
+func (model ColorModel) String() string {
+	return string(model)
+}
+
+// Reports whether the model is one of CMYK, RGB, LAB or Gray.
+func (model ColorModel) Valid() bool {
+	switch model {
+	case CMYK, RGB, LAB, Gray:
+		return true
+	}
+	return false
+}
+
+// Returns the four character code the model is stored as in a file.
+func (model ColorModel) code() (code [4]uint8, err error) {
+	if !model.Valid() {
+		return code, ErrInvalidColorModel
+	}
+
+	//	short names are padded with spaces
+	copy(code[:], string(model)+"    ")
+
+	return
+}
+
+// Implements encoding.TextMarshaler. Only valid models can be marshalled.
+func (model ColorModel) MarshalText() ([]byte, error) {
+	if !model.Valid() {
+		return nil, ErrInvalidColorModel
+	}
+	return []byte(model), nil
+}
+
+// Implements encoding.TextUnmarshaler using ParseColorModel.
+func (model *ColorModel) UnmarshalText(text []byte) (err error) {
+	*model, err = ParseColorModel(string(text))
+	return
+}
+
+// Returns the ColorModel named s, ignoring case and surrounding spaces, so
+// both "rgb" and "RGB " give RGB.
+func ParseColorModel(s string) (ColorModel, error) {
+	s = strings.TrimSpace(s)
+	for _, model := range []ColorModel{CMYK, RGB, LAB, Gray} {
+		if strings.EqualFold(s, string(model)) {
+			return model, nil
+		}
+	}
+	return "", ErrInvalidColorModel
+}
+
+// ColorType is how a color is used: as a global, a spot or a normal color.
+//
+// Like ColorModel its underlying type is string, and ParseColorType turns
+// arbitrary strings into one.
+type ColorType string
+
+const (
+	Global ColorType = "Global"
+	Spot   ColorType = "Spot"
+	Normal ColorType = "Normal"
+)
+
+// Returns the type's name, such as "Spot".
+func (colorType ColorType) String() string {
+	return string(colorType)
+}
+
+// Reports whether the type is one of Global, Spot or Normal.
+func (colorType ColorType) Valid() bool {
+	_, err := colorType.code()
+	return err == nil
+}
+
+// Returns the number the type is stored as in a file.
+func (colorType ColorType) code() (int16, error) {
+	switch colorType {
+	case Global:
+		return 0, nil
+	case Spot:
+		return 1, nil
+	case Normal:
+		return 2, nil
+	}
+	return 0, ErrInvalidColorType
+}
+
+// Implements encoding.TextMarshaler. Only valid types can be marshalled.
+func (colorType ColorType) MarshalText() ([]byte, error) {
+	if !colorType.Valid() {
+		return nil, ErrInvalidColorType
+	}
+	return []byte(colorType), nil
+}
+
+// Implements encoding.TextUnmarshaler using ParseColorType.
+func (colorType *ColorType) UnmarshalText(text []byte) (err error) {
+	*colorType, err = ParseColorType(string(text))
+	return
+}
+
+// Returns the ColorType named s, ignoring case and surrounding spaces.
+func ParseColorType(s string) (ColorType, error) {
+	s = strings.TrimSpace(s)
+	for _, colorType := range []ColorType{Global, Spot, Normal} {
+		if strings.EqualFold(s, string(colorType)) {
+			return colorType, nil
+		}
+	}
+	return "", ErrInvalidColorType
 }
 
 // Decode an ASE color.
@@ -83,7 +210,7 @@ func (color *Color) readModel(r io.Reader) (err error) {
 	}
 
 	// Assign the string version of the `colorModel`
-	color.Model = strings.TrimSpace(string(colorModel[0:]))
+	color.Model = ColorModel(strings.TrimSpace(string(colorModel[0:])))
 
 	return
 }
@@ -91,7 +218,7 @@ func (color *Color) readModel(r io.Reader) (err error) {
 // Decode the color's values.
 func (color *Color) readValues(r io.Reader) (err error) {
 	switch color.Model {
-	case "RGB":
+	case RGB:
 		rgb := make([]float32, 3)
 
 		//	read into rbg array
@@ -100,7 +227,7 @@ func (color *Color) readValues(r io.Reader) (err error) {
 		}
 		color.Values = rgb
 		break
-	case "LAB":
+	case LAB:
 		lab := make([]float32, 3)
 
 		//	read into lab array
@@ -110,7 +237,7 @@ func (color *Color) readValues(r io.Reader) (err error) {
 
 		color.Values = lab
 		break
-	case "CMYK":
+	case CMYK:
 		cmyk := make([]float32, 4)
 
 		//	read into cmyk array
@@ -120,7 +247,7 @@ func (color *Color) readValues(r io.Reader) (err error) {
 
 		color.Values = cmyk
 		break
-	case "Gray":
+	case Gray:
 		gray := make([]float32, 1)
 
 		//	read into gray array
@@ -149,13 +276,13 @@ func (color *Color) readType(r io.Reader) (err error) {
 
 	switch colorType[0] {
 	case 0:
-		color.Type = "Global"
+		color.Type = Global
 		break
 	case 1:
-		color.Type = "Spot"
+		color.Type = Spot
 		break
 	case 2:
-		color.Type = "Normal"
+		color.Type = Normal
 		break
 	default:
 		return ErrInvalidColorType
@@ -240,8 +367,13 @@ func (color *Color) calculateBlockLength() (blockLength int32, err error) {
 
 // Encode the color's name length.
 func (color *Color) writeNameLen(w io.Writer) (err error) {
+	n := nameLen(color.Name)
+	if n > maxNameLen {
+		return ErrNameTooLong
+	}
+
 	// Adding one to the name length accounts for the zero-terminated character.
-	return binary.Write(w, binary.BigEndian, color.NameLen()+1)
+	return binary.Write(w, binary.BigEndian, uint16(n+1))
 }
 
 // Encode the color's name as a slice of uint16.
@@ -251,22 +383,11 @@ func (color *Color) writeName(w io.Writer) (err error) {
 	return binary.Write(w, binary.BigEndian, name)
 }
 
-// Encode the color's model as a of slice of uint8.
+// Encode the color's model as its four character code.
 func (color *Color) writeModel(w io.Writer) (err error) {
-	model := make([]uint8, 4)
-
-	// Populate model with the uint8 version of the each character in the string.
-	for i, _ := range model {
-		colorModel := color.Model
-
-		// Our iterator over model needs to match the number of elements in color.Model.
-		// If color.Model has a length less than four, append an empty space.
-		if len(color.Model) < 4 {
-			for len(colorModel) < 4 {
-				colorModel += " "
-			}
-		}
-		model[i] = uint8([]rune(colorModel)[i])
+	model, err := color.Model.code()
+	if err != nil {
+		return
 	}
 
 	return binary.Write(w, binary.BigEndian, model)
@@ -279,32 +400,41 @@ func (color *Color) writeValues(w io.Writer) (err error) {
 
 // Encode the color's type.
 func (color *Color) writeType(w io.Writer) (err error) {
-
-	var colorType int16
-
-	switch color.Type {
-	case "Global":
-		colorType = 0
-		break
-	case "Spot":
-		colorType = 1
-		break
-	case "Normal":
-		colorType = 2
-		break
-	default:
-		return ErrInvalidColorType
+	colorType, err := color.Type.code()
+	if err != nil {
+		return
 	}
 
 	return binary.Write(w, binary.BigEndian, []int16{colorType})
 }
 
-// Helper function that returns the length of a color's name.
+// Helper function that returns the length of a color's name in UTF-16 code
+// units, the way it is stored in a file. Names too long to be stored, see
+// Validate, don't fit the result.
 func (color *Color) NameLen() uint16 {
-	return uint16(len(color.Name))
+	return uint16(nameLen(color.Name))
+}
+
+// Returns the number of UTF-16 code units name encodes to. Runes outside the
+// Basic Multilingual Plane take a surrogate pair, everything else, including
+// invalid UTF-8 which is encoded as U+FFFD, takes a single unit.
+func nameLen(name string) (n int) {
+	for _, r := range name {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return
 }
 
 // Write color's block header as a part of the ASE encoding.
 func (color *Color) writeBlockType(w io.Writer) (err error) {
 	return binary.Write(w, binary.BigEndian, colorEntry)
 }
+
+// A color is encoded as a single block.
+func (color *Color) blockCount() int32 {
+	return 1
+}
@@ -0,0 +1,153 @@
+package ase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseColorModel(t *testing.T) {
+	tests := map[string]ColorModel{
+		"RGB":  RGB,
+		"rgb":  RGB,
+		"LAB ": LAB,
+		"cmyk": CMYK,
+		"GRAY": Gray,
+	}
+
+	for s, expected := range tests {
+		model, err := ParseColorModel(s)
+		if err != nil {
+			t.Error(s, err)
+		}
+		if model != expected {
+			t.Error("expected", expected, "got", model)
+		}
+	}
+
+	if _, err := ParseColorModel("HSV"); err != ErrInvalidColorModel {
+		t.Error("expected", ErrInvalidColorModel, "got", err)
+	}
+	if _, err := ParseColorType("Process"); err != ErrInvalidColorType {
+		t.Error("expected", ErrInvalidColorType, "got", err)
+	}
+}
+
+func TestColorModelText(t *testing.T) {
+	var v struct {
+		Model ColorModel
+		Type  ColorType
+	}
+
+	if err := json.Unmarshal([]byte(`{"Model":"cmyk","Type":"spot"}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Model != CMYK || v.Type != Spot {
+		t.Error("expected CMYK Spot, got", v.Model, v.Type)
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"Model":"CMYK","Type":"Spot"}` {
+		t.Error("unexpected JSON", string(out))
+	}
+
+	v.Model = "rgb"
+	if _, err = json.Marshal(v); err == nil {
+		t.Error("expected an error marshalling an invalid model")
+	}
+}
+
+func TestEncodeInvalidModel(t *testing.T) {
+	sampleAse := ASE{}
+	sampleAse.Colors = []Color{{Name: "typo", Model: "rgb", Values: []float32{1, 1, 1}, Type: Global}}
+
+	if err := Encode(sampleAse, new(bytes.Buffer)); !errors.Is(err, ErrInvalidColorModel) {
+		t.Error("expected", ErrInvalidColorModel, "got", err)
+	}
+
+	// Without validation, the model is still checked when it's written.
+	err := EncodeWithOptions(sampleAse, new(bytes.Buffer), EncoderOptions{SkipValidation: true})
+	if err != ErrInvalidColorModel {
+		t.Error("expected", ErrInvalidColorModel, "got", err)
+	}
+
+	sampleAse.Colors[0].Model = RGB
+	sampleAse.Colors[0].Type = "global"
+	if err := Encode(sampleAse, new(bytes.Buffer)); !errors.Is(err, ErrInvalidColorType) {
+		t.Error("expected", ErrInvalidColorType, "got", err)
+	}
+}
+
+func TestNameRoundTrip(t *testing.T) {
+	names := []string{
+		"Café crème",   // Latin with accents
+		"Красный",      // Cyrillic
+		"赤い色",          // CJK
+		"أحمر",         // Arabic
+		"🎨 Palette 🖌️", // emoji, outside the BMP
+		"𝄞 clef",       // musical symbol, outside the BMP
+		"mixed ü 中 😀 end",
+	}
+
+	expectedLen := []uint16{10, 7, 3, 4, 14, 7, 16}
+
+	sampleAse := ASE{}
+	for i, name := range names {
+		c := Color{Name: name, Model: RGB, Values: []float32{1, 0, 0}, Type: Global}
+		if c.NameLen() != expectedLen[i] {
+			t.Errorf("expected %q to be %d UTF-16 units, got %d", name, expectedLen[i], c.NameLen())
+		}
+		sampleAse.Colors = append(sampleAse.Colors, c)
+	}
+	sampleAse.Groups = []Group{{Name: "グループ 🌈", Colors: sampleAse.Colors}}
+
+	b := new(bytes.Buffer)
+	if err := Encode(sampleAse, b); err != nil {
+		t.Fatal(err)
+	}
+	ase, err := Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, name := range names {
+		if ase.Colors[i].Name != name {
+			t.Errorf("expected %q, got %q", name, ase.Colors[i].Name)
+		}
+		if ase.Groups[0].Colors[i].Name != name {
+			t.Errorf("expected %q in group, got %q", name, ase.Groups[0].Colors[i].Name)
+		}
+	}
+	if ase.Groups[0].Name != "グループ 🌈" {
+		t.Errorf("unexpected group name %q", ase.Groups[0].Name)
+	}
+}
+
+func TestNameTooLong(t *testing.T) {
+	// Each emoji takes two units, so this is one unit over the limit.
+	c := Color{Name: strings.Repeat("😀", maxNameLen/2) + "a", Model: Gray, Values: []float32{0}, Type: Normal}
+	if int(nameLen(c.Name)) != maxNameLen+1 {
+		t.Fatal("unexpected name length", nameLen(c.Name))
+	}
+
+	if err := c.Validate(); !errors.Is(err, ErrNameTooLong) {
+		t.Error("expected", ErrNameTooLong, "got", err)
+	}
+
+	sampleAse := ASE{Colors: []Color{c}}
+	err := EncodeWithOptions(sampleAse, new(bytes.Buffer), EncoderOptions{SkipValidation: true})
+	if err != ErrNameTooLong {
+		t.Error("expected", ErrNameTooLong, "got", err)
+	}
+
+	// Exactly at the limit is fine.
+	c.Name = c.Name[:len(c.Name)-1]
+	if err := c.Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,212 @@
+package ase
+
+import (
+	"math"
+)
+
+// Returns the WCAG 2 contrast ratio between two colors, from 1 for the same
+// luminance to 21 for black and white. Colors are converted to sRGB first.
+//
+// WCAG 2 asks for 4.5 for normal text and 3 for large text to meet AA, and
+// for 7 and 4.5 to meet AAA.
+func ContrastRatio(c1, c2 Color) (float64, error) {
+	if err := c1.checkValues(); err != nil {
+		return 0, err
+	}
+	if err := c2.checkValues(); err != nil {
+		return 0, err
+	}
+
+	return contrastRatio(luminance(c1.rgb(NaiveCMYK{})), luminance(c2.rgb(NaiveCMYK{}))), nil
+}
+
+// Returns the APCA lightness contrast, Lc, of text over background, following
+// APCA-W3 0.0.98G. It goes from about 106 for black text on white to about
+// -108 for white text on black, negative values being light text on a dark
+// background. Unlike ContrastRatio, it depends on which color is the text.
+//
+// APCA suggests an Lc of 75 for body text, 60 for other content text and 45
+// for large text, in either direction.
+func APCAContrast(text, background Color) (float64, error) {
+	if err := text.checkValues(); err != nil {
+		return 0, err
+	}
+	if err := background.checkValues(); err != nil {
+		return 0, err
+	}
+
+	return apca(apcaLuminance(text.rgb(NaiveCMYK{})), apcaLuminance(background.rgb(NaiveCMYK{}))), nil
+}
+
+// A pair of colors of a palette, one used as text over the other, and their
+// contrast.
+type ContrastPair struct {
+	Text            Color  `json:"text"`
+	TextGroup       string `json:"textGroup,omitempty"`
+	Background      Color  `json:"background"`
+	BackgroundGroup string `json:"backgroundGroup,omitempty"`
+
+	// Ratio is the WCAG 2 contrast ratio, see ContrastRatio, and Lc the
+	// APCA contrast, see APCAContrast.
+	Ratio float64 `json:"ratio"`
+	Lc    float64 `json:"lc"`
+
+	// The WCAG 2 levels the pair meets, for normal and large text.
+	AA       bool `json:"aa"`
+	AALarge  bool `json:"aaLarge"`
+	AAA      bool `json:"aaa"`
+	AAALarge bool `json:"aaaLarge"`
+}
+
+// ContrastOptions picks the pairs ContrastMatrix checks.
+type ContrastOptions struct {
+	// TextGroups and BackgroundGroups name the groups whose colors are
+	// used as text and as background, an empty name standing for loose
+	// colors. Every color is used when they're empty.
+	TextGroups       []string
+	BackgroundGroups []string
+}
+
+// Returns the contrast of every text color over every background color, in
+// order of text then background. A color isn't paired with itself, and
+// colors that can't be converted to sRGB are left out.
+func ContrastMatrix(ase ASE, opts ContrastOptions) (pairs []ContrastPair) {
+	type contrastColor struct {
+		color *Color
+		group string
+		rgb   [3]float64
+	}
+
+	var texts, backgrounds []contrastColor
+	ase.eachColor(func(group *Group, color *Color) {
+		if color.checkValues() != nil {
+			return
+		}
+
+		c := contrastColor{color: color}
+		if group != nil {
+			c.group = group.Name
+		}
+		c.rgb[0], c.rgb[1], c.rgb[2] = color.rgb(NaiveCMYK{})
+
+		if selected(opts.TextGroups, c.group) {
+			texts = append(texts, c)
+		}
+		if selected(opts.BackgroundGroups, c.group) {
+			backgrounds = append(backgrounds, c)
+		}
+	})
+
+	for _, text := range texts {
+		for _, background := range backgrounds {
+			if text.color == background.color {
+				continue
+			}
+
+			ratio := contrastRatio(luminance(text.rgb[0], text.rgb[1], text.rgb[2]),
+				luminance(background.rgb[0], background.rgb[1], background.rgb[2]))
+			lc := apca(apcaLuminance(text.rgb[0], text.rgb[1], text.rgb[2]),
+				apcaLuminance(background.rgb[0], background.rgb[1], background.rgb[2]))
+
+			pairs = append(pairs, ContrastPair{
+				Text:            *copyColor(text.color),
+				TextGroup:       text.group,
+				Background:      *copyColor(background.color),
+				BackgroundGroup: background.group,
+				Ratio:           ratio,
+				Lc:              lc,
+				AA:              ratio >= 4.5,
+				AALarge:         ratio >= 3,
+				AAA:             ratio >= 7,
+				AAALarge:        ratio >= 4.5,
+			})
+		}
+	}
+
+	return
+}
+
+// Reports whether group is one of groups, or groups is empty.
+func selected(groups []string, group string) bool {
+	if len(groups) == 0 {
+		return true
+	}
+	for _, g := range groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the WCAG 2 relative luminance of an sRGB color.
+func luminance(r, g, b float64) float64 {
+	return 0.2126*linearize(r) + 0.7152*linearize(g) + 0.0722*linearize(b)
+}
+
+// Returns the WCAG 2 contrast ratio between two relative luminances, from 1
+// to 21.
+func contrastRatio(l1, l2 float64) float64 {
+	return (math.Max(l1, l2) + 0.05) / (math.Min(l1, l2) + 0.05)
+}
+
+// Returns the best WCAG 2 level a contrast ratio meets: "AAA", "AA", "AA
+// large" for large text only, or "fail".
+func wcagGrade(ratio float64) string {
+	switch {
+	case ratio >= 7:
+		return "AAA"
+	case ratio >= 4.5:
+		return "AA"
+	case ratio >= 3:
+		return "AA large"
+	}
+	return "fail"
+}
+
+// Returns the screen luminance APCA works with, which uses a plain 2.4 gamma.
+func apcaLuminance(r, g, b float64) float64 {
+	return 0.2126729*math.Pow(r, 2.4) + 0.7151522*math.Pow(g, 2.4) + 0.0721750*math.Pow(b, 2.4)
+}
+
+// Returns the APCA Lc of text over background from their screen luminances.
+func apca(text, background float64) float64 {
+	const (
+		blackThreshold = 0.022
+		blackClamp     = 1.414
+		deltaYMin      = 0.0005
+		scale          = 1.14
+		lowClip        = 0.1
+		lowOffset      = 0.027
+	)
+
+	//	soft clamp near black, where screens flare
+	clamp := func(y float64) float64 {
+		if y < blackThreshold {
+			y += math.Pow(blackThreshold-y, blackClamp)
+		}
+		return y
+	}
+	text, background = clamp(text), clamp(background)
+
+	if math.Abs(background-text) < deltaYMin {
+		return 0
+	}
+
+	var sapc float64
+	if background > text {
+		//	dark text on a light background
+		sapc = (math.Pow(background, 0.56) - math.Pow(text, 0.57)) * scale
+		if sapc < lowClip {
+			return 0
+		}
+		return (sapc - lowOffset) * 100
+	}
+
+	//	light text on a dark background
+	sapc = (math.Pow(background, 0.65) - math.Pow(text, 0.62)) * scale
+	if sapc > -lowClip {
+		return 0
+	}
+	return (sapc + lowOffset) * 100
+}
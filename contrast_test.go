@@ -0,0 +1,104 @@
+package ase
+
+import (
+	"math"
+	"testing"
+)
+
+// Returns an RGB color from 8 bit components.
+func rgb8(name string, r, g, b uint8) Color {
+	return Color{Name: name, Model: RGB, Values: []float32{float32(r) / 0xff, float32(g) / 0xff, float32(b) / 0xff}, Type: Normal}
+}
+
+func TestContrastRatio(t *testing.T) {
+	white, black := rgb8("White", 0xff, 0xff, 0xff), rgb8("Black", 0, 0, 0)
+
+	for _, test := range []struct {
+		c1, c2   Color
+		expected float64
+	}{
+		{black, white, 21},
+		{white, black, 21},
+		{white, white, 1},
+		{rgb8("Red", 0xff, 0, 0), white, 3.998},
+		{rgb8("Gray", 0x77, 0x77, 0x77), white, 4.478},
+		{Color{Model: CMYK, Values: []float32{0, 0, 0, 1}}, Color{Model: Gray, Values: []float32{1}}, 21},
+	} {
+		ratio, err := ContrastRatio(test.c1, test.c2)
+		if err != nil || math.Abs(ratio-test.expected) > 0.001 {
+			t.Errorf("%v on %v: expected %.3f, got %.3f %v", test.c1.Values, test.c2.Values, test.expected, ratio, err)
+		}
+	}
+
+	if _, err := ContrastRatio(white, Color{Model: RGB}); err == nil {
+		t.Error("expected an error for a color without values")
+	}
+}
+
+func TestAPCAContrast(t *testing.T) {
+	// Values from the APCA reference implementation's tests.
+	for _, test := range []struct {
+		text, background Color
+		expected         float64
+	}{
+		{rgb8("", 0x88, 0x88, 0x88), rgb8("", 0xff, 0xff, 0xff), 63.0565},
+		{rgb8("", 0xff, 0xff, 0xff), rgb8("", 0x88, 0x88, 0x88), -68.5415},
+		{rgb8("", 0, 0, 0), rgb8("", 0xaa, 0xaa, 0xaa), 58.1463},
+		{rgb8("", 0xaa, 0xaa, 0xaa), rgb8("", 0, 0, 0), -56.2411},
+		{rgb8("", 0, 0, 0), rgb8("", 0xff, 0xff, 0xff), 106.0406},
+		{rgb8("", 0xff, 0xff, 0xff), rgb8("", 0, 0, 0), -107.8847},
+		{rgb8("", 0x12, 0x34, 0x56), rgb8("", 0x12, 0x34, 0x56), 0},
+	} {
+		lc, err := APCAContrast(test.text, test.background)
+		if err != nil || math.Abs(lc-test.expected) > 0.001 {
+			t.Errorf("%v on %v: expected %.4f, got %.4f %v", test.text.Values, test.background.Values, test.expected, lc, err)
+		}
+	}
+}
+
+func TestContrastMatrix(t *testing.T) {
+	palette := ASE{
+		Colors: []Color{
+			rgb8("White", 0xff, 0xff, 0xff),
+			{Name: "Broken", Model: RGB, Values: []float32{1}, Type: Normal},
+		},
+		Groups: []Group{
+			{Name: "Text", Colors: []Color{rgb8("Ink", 0, 0, 0), rgb8("Muted", 0x77, 0x77, 0x77)}},
+			{Name: "Surfaces", Colors: []Color{rgb8("Paper", 0xff, 0xff, 0xff), rgb8("Red", 0xff, 0, 0)}},
+		},
+	}
+
+	pairs := ContrastMatrix(palette, ContrastOptions{})
+	if len(pairs) != 5*4 {
+		t.Fatal("expected every ordered pair of 5 colors, got", len(pairs))
+	}
+	if p := pairs[0]; p.Text.Name != "White" || p.Background.Name != "Ink" || p.BackgroundGroup != "Text" || p.Ratio != 21 || !p.AAA {
+		t.Error("unexpected first pair", p)
+	}
+
+	pairs = ContrastMatrix(palette, ContrastOptions{TextGroups: []string{"Text"}, BackgroundGroups: []string{"Surfaces", ""}})
+	expected := []struct {
+		text, background string
+		aa, aaLarge, aaa bool
+	}{
+		{"Ink", "White", true, true, true},
+		{"Ink", "Paper", true, true, true},
+		{"Ink", "Red", true, true, false},
+		{"Muted", "White", false, true, false},
+		{"Muted", "Paper", false, true, false},
+		{"Muted", "Red", false, false, false},
+	}
+	if len(pairs) != len(expected) {
+		t.Fatal("expected", len(expected), "pairs, got", pairs)
+	}
+	for i, e := range expected {
+		p := pairs[i]
+		if p.Text.Name != e.text || p.Background.Name != e.background || p.AA != e.aa || p.AALarge != e.aaLarge || p.AAA != e.aaa || p.AAALarge != p.AA {
+			t.Errorf("pair %d: expected %v, got %+v", i, e, p)
+		}
+		// Gray on red is too close to call, APCA clips it to 0.
+		if p.Lc < 0 || (p.Lc == 0) != (i == 5) {
+			t.Errorf("pair %d: expected dark text on a light background, got Lc %f", i, p.Lc)
+		}
+	}
+}
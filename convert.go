@@ -0,0 +1,245 @@
+package ase
+
+import (
+	"fmt"
+	"math"
+)
+
+// A CMYKConverter converts between CMYK and sRGB. All components go from 0 to 1.
+//
+// Proper CMYK conversions depend on the press, paper and inks, and so on an
+// ICC profile. Implement this interface to plug one in.
+type CMYKConverter interface {
+	CMYKToRGB(c, m, y, k float64) (r, g, b float64)
+	RGBToCMYK(r, g, b float64) (c, m, y, k float64)
+}
+
+// NaiveCMYK converts between CMYK and sRGB with the device independent
+// formulas: r = (1-c)(1-k) and so on, taking as much black as possible the
+// other way. It is what Color's To methods use.
+type NaiveCMYK struct{}
+
+func (NaiveCMYK) CMYKToRGB(c, m, y, k float64) (r, g, b float64) {
+	return (1 - c) * (1 - k), (1 - m) * (1 - k), (1 - y) * (1 - k)
+}
+
+func (NaiveCMYK) RGBToCMYK(r, g, b float64) (c, m, y, k float64) {
+	k = 1 - math.Max(r, math.Max(g, b))
+	if k == 1 {
+		return 0, 0, 0, 1
+	}
+	return (1 - r - k) / (1 - k), (1 - g - k) / (1 - k), (1 - b - k) / (1 - k), k
+}
+
+// Returns the color converted to the RGB model. RGB values are sRGB.
+func (color *Color) ToRGB() (Color, error) {
+	return color.ConvertWith(RGB, NaiveCMYK{})
+}
+
+// Returns the color converted to the LAB model. LAB values are CIELAB relative
+// to a D50 white point, with lightness divided by 100 like in ASE files.
+func (color *Color) ToLab() (Color, error) {
+	return color.ConvertWith(LAB, NaiveCMYK{})
+}
+
+// Returns the color converted to the CMYK model, see NaiveCMYK.
+func (color *Color) ToCMYK() (Color, error) {
+	return color.ConvertWith(CMYK, NaiveCMYK{})
+}
+
+// Returns the color converted to the Gray model. Gray values go from black at
+// 0 to white at 1, and the conversion keeps the color's luminance.
+func (color *Color) ToGray() (Color, error) {
+	return color.ConvertWith(Gray, NaiveCMYK{})
+}
+
+// Returns the color converted to model, with the same name and type.
+//
+// Conversions go through sRGB, except between RGB and LAB which are related
+// by the sRGB matrix and a Bradford adaptation from D65 to D50. Colors that
+// fall outside of sRGB are clipped. cmyk converts to and from CMYK.
+func (color *Color) ConvertWith(model ColorModel, cmyk CMYKConverter) (converted Color, err error) {
+	if err = color.checkValues(); err != nil {
+		return
+	}
+
+	converted = Color{Name: color.Name, Model: model, Type: color.Type}
+
+	//	nothing to convert, just don't share the values
+	if model == color.Model {
+		converted.Values = append([]float32{}, color.Values...)
+		return
+	}
+
+	//	lab doesn't need to be clipped into sRGB
+	if model == LAB {
+		l, a, b := color.lab(cmyk)
+		converted.Values = []float32{float32(l / 100), float32(a), float32(b)}
+		return
+	}
+
+	r, g, b := color.rgb(cmyk)
+	switch model {
+	case RGB:
+		converted.Values = []float32{float32(r), float32(g), float32(b)}
+	case CMYK:
+		c, m, y, k := cmyk.RGBToCMYK(r, g, b)
+		converted.Values = []float32{float32(c), float32(m), float32(y), float32(k)}
+	case Gray:
+		//	re-encoding the luminance makes gray to rgb to gray lossless
+		y := 0.2126729*linearize(r) + 0.7151522*linearize(g) + 0.0721750*linearize(b)
+		converted.Values = []float32{float32(delinearize(y))}
+	default:
+		err = ErrInvalidColorModel
+	}
+
+	return
+}
+
+// Checks the color has a known model and as many values as it takes.
+func (color *Color) checkValues() error {
+	if !color.Model.Valid() {
+		return ErrInvalidColorModel
+	}
+	if n := len(color.Model.ranges()); len(color.Values) != n {
+		return fmt.Errorf("%w: %s takes %d, got %d", ErrValueCount, color.Model, n, len(color.Values))
+	}
+	return nil
+}
+
+// Returns the color as sRGB, clipped to [0, 1]. The color's values must have
+// been checked.
+func (color *Color) rgb(cmyk CMYKConverter) (r, g, b float64) {
+	v := color.Values
+
+	switch color.Model {
+	case RGB:
+		r, g, b = float64(v[0]), float64(v[1]), float64(v[2])
+	case CMYK:
+		r, g, b = cmyk.CMYKToRGB(float64(v[0]), float64(v[1]), float64(v[2]), float64(v[3]))
+	case Gray:
+		r, g, b = float64(v[0]), float64(v[0]), float64(v[0])
+	case LAB:
+		r, g, b = labToRGB(float64(v[0])*100, float64(v[1]), float64(v[2]))
+	}
+
+	return clip(r), clip(g), clip(b)
+}
+
+// Returns the color as CIELAB relative to D50, lightness going from 0 to 100.
+// The color's values must have been checked.
+func (color *Color) lab(cmyk CMYKConverter) (l, a, b float64) {
+	if color.Model == LAB {
+		return float64(color.Values[0]) * 100, float64(color.Values[1]), float64(color.Values[2])
+	}
+	return rgbToLab(color.rgb(cmyk))
+}
+
+// D50 reference white, the one ASE and ICC profiles use for LAB.
+const (
+	whiteX = 0.96422
+	whiteY = 1.0
+	whiteZ = 0.82521
+)
+
+// Converts CIELAB relative to D50 to unclipped sRGB.
+func labToRGB(l, a, b float64) (float64, float64, float64) {
+	r, g, bl := xyzToLinearRGB(d50ToD65(labToXYZ(l, a, b)))
+	return delinearize(r), delinearize(g), delinearize(bl)
+}
+
+// Converts sRGB to CIELAB relative to D50.
+func rgbToLab(r, g, b float64) (float64, float64, float64) {
+	return xyzToLab(d65ToD50(linearRGBToXYZ(linearize(r), linearize(g), linearize(b))))
+}
+
+// Converts linear sRGB to XYZ relative to D65.
+func linearRGBToXYZ(r, g, b float64) (x, y, z float64) {
+	return 0.4124564*r + 0.3575761*g + 0.1804375*b,
+		0.2126729*r + 0.7151522*g + 0.0721750*b,
+		0.0193339*r + 0.1191920*g + 0.9503041*b
+}
+
+// Converts XYZ relative to D65 to linear sRGB.
+func xyzToLinearRGB(x, y, z float64) (r, g, b float64) {
+	return 3.2404542*x - 1.5371385*y - 0.4985314*z,
+		-0.9692660*x + 1.8760108*y + 0.0415560*z,
+		0.0556434*x - 0.2040259*y + 1.0572252*z
+}
+
+// Bradford adaptation of XYZ from D50 to D65.
+func d50ToD65(x, y, z float64) (float64, float64, float64) {
+	return 0.9555766*x - 0.0230393*y + 0.0631636*z,
+		-0.0282895*x + 1.0099416*y + 0.0210077*z,
+		0.0122982*x - 0.0204830*y + 1.3299098*z
+}
+
+// Bradford adaptation of XYZ from D65 to D50.
+func d65ToD50(x, y, z float64) (float64, float64, float64) {
+	return 1.0478112*x + 0.0228866*y - 0.0501270*z,
+		0.0295424*x + 0.9904844*y - 0.0170491*z,
+		-0.0092345*x + 0.0150436*y + 0.7521316*z
+}
+
+// CIE constants, in their exact rational form.
+const (
+	labEpsilon = 216.0 / 24389
+	labKappa   = 24389.0 / 27
+)
+
+// Converts CIELAB to XYZ, both relative to D50.
+func labToXYZ(l, a, b float64) (x, y, z float64) {
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - b/200
+
+	finv := func(f float64) float64 {
+		if f3 := f * f * f; f3 > labEpsilon {
+			return f3
+		}
+		return (116*f - 16) / labKappa
+	}
+
+	if l > labKappa*labEpsilon {
+		y = fy * fy * fy
+	} else {
+		y = l / labKappa
+	}
+
+	return finv(fx) * whiteX, y * whiteY, finv(fz) * whiteZ
+}
+
+// Converts XYZ to CIELAB, both relative to D50.
+func xyzToLab(x, y, z float64) (l, a, b float64) {
+	f := func(t float64) float64 {
+		if t > labEpsilon {
+			return math.Cbrt(t)
+		}
+		return (labKappa*t + 16) / 116
+	}
+
+	fx, fy, fz := f(x/whiteX), f(y/whiteY), f(z/whiteZ)
+
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+// Removes the sRGB transfer function.
+func linearize(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// Applies the sRGB transfer function.
+func delinearize(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// Clips v to [0, 1].
+func clip(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
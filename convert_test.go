@@ -0,0 +1,94 @@
+package ase
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func closeTo(a, b []float32, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(float64(a[i]-b[i])) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		from     Color
+		to       ColorModel
+		expected []float32
+	}{
+		// Reference values for sRGB to D50 LAB with Bradford adaptation.
+		{Color{Model: RGB, Values: []float32{1, 1, 1}}, LAB, []float32{1, 0, 0}},
+		{Color{Model: RGB, Values: []float32{1, 0, 0}}, LAB, []float32{0.54291, 80.805, 69.891}},
+		{Color{Model: RGB, Values: []float32{0, 0, 1}}, LAB, []float32{0.29568, 68.299, -112.03}},
+		{Color{Model: LAB, Values: []float32{0.54291, 80.805, 69.891}}, RGB, []float32{1, 0, 0}},
+		{Color{Model: LAB, Values: []float32{0.5, 0, 0}}, Gray, []float32{0.46630}},
+
+		{Color{Model: CMYK, Values: []float32{0, 1, 0, 0}}, RGB, []float32{1, 0, 1}},
+		{Color{Model: CMYK, Values: []float32{0, 0, 0, 0.5}}, RGB, []float32{0.5, 0.5, 0.5}},
+		{Color{Model: RGB, Values: []float32{0, 0.5, 0.5}}, CMYK, []float32{1, 0, 0, 0.5}},
+		{Color{Model: RGB, Values: []float32{0, 0, 0}}, CMYK, []float32{0, 0, 0, 1}},
+
+		{Color{Model: Gray, Values: []float32{0.25}}, RGB, []float32{0.25, 0.25, 0.25}},
+		{Color{Model: RGB, Values: []float32{0.25, 0.25, 0.25}}, Gray, []float32{0.25}},
+		{Color{Model: RGB, Values: []float32{1, 0, 0}}, Gray, []float32{0.49895}},
+
+		// Out of gamut LAB gets clipped on the way to RGB.
+		{Color{Model: LAB, Values: []float32{0.9137255, -5, 94}}, RGB, []float32{1, 0.905, 0}},
+	}
+
+	for _, test := range tests {
+		test.from.Name = "swatch"
+		test.from.Type = Spot
+
+		converted, err := test.from.ConvertWith(test.to, NaiveCMYK{})
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if converted.Model != test.to || converted.Name != "swatch" || converted.Type != Spot {
+			t.Errorf("unexpected model, name or type: %+v", converted)
+		}
+		if !closeTo(converted.Values, test.expected, 0.01) {
+			t.Errorf("%s %v to %s: expected %v, got %v", test.from.Model, test.from.Values, test.to, test.expected, converted.Values)
+		}
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	for _, v := range [][]float32{{0.2, 0.4, 0.6}, {0.9, 0.1, 0.3}, {0, 0, 0}, {1, 1, 1}} {
+		c := Color{Model: RGB, Values: v}
+
+		lab, err := c.ToLab()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rgb, err := lab.ToRGB()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !closeTo(rgb.Values, v, 1e-4) {
+			t.Error("expected", v, "got", rgb.Values, "through", lab.Values)
+		}
+	}
+}
+
+func TestConvertInvalid(t *testing.T) {
+	c := Color{Model: RGB, Values: []float32{1, 1}}
+	if _, err := c.ToLab(); !errors.Is(err, ErrValueCount) {
+		t.Error("expected", ErrValueCount, "got", err)
+	}
+
+	c = Color{Model: "HSB", Values: []float32{1, 1, 1}}
+	if _, err := c.ToRGB(); err != ErrInvalidColorModel {
+		t.Error("expected", ErrInvalidColorModel, "got", err)
+	}
+}
@@ -0,0 +1,574 @@
+package ase
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidCSSColor = errors.New("ase: invalid CSS color")
+
+// ParseColor returns the color described by s, in any CSS Color Level 4
+// notation: hex codes, named colors, rgb(), hsl(), hwb(), lab(), lch(),
+// oklab(), oklch() and color() in the srgb, srgb-linear, display-p3, xyz,
+// xyz-d50 and xyz-d65 color spaces. Both the legacy comma separated and the
+// space separated syntaxes are understood.
+//
+// Notations based on sRGB give an RGB color. The others give a LAB color, so
+// colors outside of sRGB aren't clipped, except that values out of the ranges
+// ASE allows are. Alpha is parsed but dropped, ASE colors are always opaque.
+// The color has no name and is of type Normal.
+func ParseColor(s string) (color Color, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	switch {
+	case strings.HasPrefix(s, "#"):
+		color, err = parseHexColor(s[1:])
+	case strings.HasSuffix(s, ")"):
+		color, err = parseColorFunction(s)
+	default:
+		rgb, ok := namedColors[s]
+		if !ok {
+			return color, fmt.Errorf("%w: unknown color %q", ErrInvalidCSSColor, s)
+		}
+		color = rgbColor(float64(rgb[0])/255, float64(rgb[1])/255, float64(rgb[2])/255)
+	}
+
+	color.Type = Normal
+
+	return
+}
+
+// Parses a #rgb, #rgba, #rrggbb or #rrggbbaa hex code, without the #.
+func parseHexColor(hex string) (color Color, err error) {
+	//	expand the short forms
+	if len(hex) == 3 || len(hex) == 4 {
+		long := make([]byte, 0, 2*len(hex))
+		for i := 0; i < len(hex); i++ {
+			long = append(long, hex[i], hex[i])
+		}
+		hex = string(long)
+	}
+	if len(hex) != 6 && len(hex) != 8 {
+		return color, fmt.Errorf("%w: hex code %q", ErrInvalidCSSColor, hex)
+	}
+
+	//	alpha is checked but dropped, ASE colors are opaque
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color, fmt.Errorf("%w: hex code %q", ErrInvalidCSSColor, hex)
+	}
+	if len(hex) == 8 {
+		v >>= 8
+	}
+
+	return rgbColor(float64(v>>16)/255, float64(v>>8&0xff)/255, float64(v&0xff)/255), nil
+}
+
+// Parses a functional notation such as rgb(255 0 0 / 50%).
+func parseColorFunction(s string) (color Color, err error) {
+	open := strings.IndexByte(s, '(')
+	if open < 0 {
+		return color, fmt.Errorf("%w: %q", ErrInvalidCSSColor, s)
+	}
+	name := strings.TrimSpace(s[:open])
+
+	//	commas and spaces separate arguments alike, alpha follows a slash
+	body := strings.NewReplacer(",", " ", "/", " / ").Replace(s[open+1 : len(s)-1])
+	args := strings.Fields(body)
+	for i, arg := range args {
+		if arg == "/" {
+			args = args[:i]
+			break
+		}
+	}
+
+	//	the color space of color() comes first
+	space := ""
+	if name == "color" && len(args) > 0 {
+		space, args = args[0], args[1:]
+	}
+
+	//	with the legacy syntax, alpha is a fourth argument
+	if (name == "rgba" || name == "hsla" || name == "rgb" || name == "hsl") && len(args) == 4 {
+		args = args[:3]
+	}
+	if len(args) != 3 {
+		return color, fmt.Errorf("%w: %s() takes 3 components, got %d", ErrInvalidCSSColor, name, len(args))
+	}
+
+	//	parse every component up front, the hue of the notations with one
+	//	is parsed as an angle
+	var v [3]float64
+	hueAt := -1
+	switch name {
+	case "hsl", "hsla", "hwb":
+		hueAt = 0
+	case "lch", "oklch":
+		hueAt = 2
+	}
+	scale := cssPercentScales[name]
+	if name == "color" {
+		scale = [3]float64{1, 1, 1}
+	}
+	for i, arg := range args {
+		if i == hueAt {
+			v[i], err = parseHue(arg)
+		} else {
+			v[i], err = parseComponent(arg, scale[i])
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	switch name {
+	case "rgb", "rgba":
+		return rgbColor(v[0]/255, v[1]/255, v[2]/255), nil
+	case "hsl", "hsla":
+		r, g, b := hslToRGB(v[0], v[1]/100, v[2]/100)
+		return rgbColor(r, g, b), nil
+	case "hwb":
+		r, g, b := hwbToRGB(v[0], v[1]/100, v[2]/100)
+		return rgbColor(r, g, b), nil
+	case "lab":
+		return labColor(v[0], v[1], v[2]), nil
+	case "lch":
+		a, b := polarToCartesian(v[1], v[2])
+		return labColor(v[0], a, b), nil
+	case "oklab":
+		return labColor(oklabToLab(v[0], v[1], v[2])), nil
+	case "oklch":
+		a, b := polarToCartesian(v[1], v[2])
+		return labColor(oklabToLab(v[0], a, b)), nil
+	case "color":
+		return parseColorSpace(space, v)
+	}
+
+	return color, fmt.Errorf("%w: unknown function %s()", ErrInvalidCSSColor, name)
+}
+
+// What 100% stands for in each component of the functional notations. Hues
+// can't be percentages. The components of color() all go up to 1.
+var cssPercentScales = map[string][3]float64{
+	"rgb":   {255, 255, 255},
+	"rgba":  {255, 255, 255},
+	"hsl":   {0, 100, 100},
+	"hsla":  {0, 100, 100},
+	"hwb":   {0, 100, 100},
+	"lab":   {100, 125, 125},
+	"lch":   {100, 150, 0},
+	"oklab": {1, 0.4, 0.4},
+	"oklch": {1, 0.4, 0},
+}
+
+// Converts the components of color() in the given color space.
+func parseColorSpace(space string, v [3]float64) (Color, error) {
+	switch space {
+	case "srgb":
+		return rgbColor(v[0], v[1], v[2]), nil
+	case "srgb-linear":
+		return rgbColor(delinearize(v[0]), delinearize(v[1]), delinearize(v[2])), nil
+	case "display-p3":
+		r, g, b := linearize(v[0]), linearize(v[1]), linearize(v[2])
+		x := 0.4865709486482162*r + 0.26566769316909306*g + 0.1982172852343625*b
+		y := 0.2289745640697488*r + 0.6917385218365064*g + 0.079286914093745*b
+		z := 0.04511338185890264*g + 1.043944368900976*b
+		return labColor(xyzToLab(d65ToD50(x, y, z))), nil
+	case "xyz", "xyz-d65":
+		return labColor(xyzToLab(d65ToD50(v[0], v[1], v[2]))), nil
+	case "xyz-d50":
+		return labColor(xyzToLab(v[0], v[1], v[2])), nil
+	}
+
+	return Color{}, fmt.Errorf("%w: unsupported color space %q", ErrInvalidCSSColor, space)
+}
+
+// Parses a number or a percentage of scale. "none" is zero.
+func parseComponent(arg string, scale float64) (float64, error) {
+	if arg == "none" {
+		return 0, nil
+	}
+
+	percent := strings.HasSuffix(arg, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(arg, "%"), 64)
+	if err != nil || !finite(v) || (percent && scale == 0) {
+		return 0, fmt.Errorf("%w: component %q", ErrInvalidCSSColor, arg)
+	}
+	if percent {
+		v *= scale / 100
+	}
+
+	return v, nil
+}
+
+// Parses a hue in degrees, or in any CSS angle unit. "none" is zero.
+func parseHue(arg string) (float64, error) {
+	if arg == "none" {
+		return 0, nil
+	}
+
+	units := []struct {
+		suffix string
+		deg    float64
+	}{
+		{"deg", 1},
+		{"grad", 0.9},
+		{"rad", 180 / math.Pi},
+		{"turn", 360},
+		{"", 1},
+	}
+
+	for _, unit := range units {
+		if !strings.HasSuffix(arg, unit.suffix) {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSuffix(arg, unit.suffix), 64)
+		if err != nil || !finite(v) {
+			break
+		}
+		return v * unit.deg, nil
+	}
+
+	return 0, fmt.Errorf("%w: hue %q", ErrInvalidCSSColor, arg)
+}
+
+// Tells whether v is neither NaN nor infinite, which ParseFloat accepts as
+// "nan" and "inf".
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// Returns an RGB color, clipped to the sRGB gamut.
+func rgbColor(r, g, b float64) Color {
+	return Color{Model: RGB, Values: []float32{float32(clip(r)), float32(clip(g)), float32(clip(b))}}
+}
+
+// Returns a LAB color, clipped to the ranges ASE allows.
+func labColor(l, a, b float64) Color {
+	limit := func(v float64) float32 {
+		return float32(math.Max(-128, math.Min(127, v)))
+	}
+	return Color{Model: LAB, Values: []float32{float32(clip(l / 100)), limit(a), limit(b)}}
+}
+
+// Converts a chroma and a hue in degrees to the a and b axes.
+func polarToCartesian(c, h float64) (a, b float64) {
+	rad := h * math.Pi / 180
+	return c * math.Cos(rad), c * math.Sin(rad)
+}
+
+// Converts the a and b axes to a chroma and a hue in degrees from 0 to 360.
+func cartesianToPolar(a, b float64) (c, h float64) {
+	c = math.Hypot(a, b)
+	h = math.Mod(math.Atan2(b, a)*180/math.Pi+360, 360)
+	return
+}
+
+// Converts HSL, saturation and lightness going from 0 to 1, to sRGB.
+func hslToRGB(h, s, l float64) (r, g, b float64) {
+	f := func(n float64) float64 {
+		k := math.Mod(n+h/30, 12)
+		a := s * math.Min(l, 1-l)
+		return l - a*math.Max(-1, math.Min(k-3, math.Min(9-k, 1)))
+	}
+	return f(0), f(8), f(4)
+}
+
+// Converts HWB, whiteness and blackness going from 0 to 1, to sRGB.
+func hwbToRGB(h, w, bl float64) (r, g, b float64) {
+	if w+bl >= 1 {
+		gray := w / (w + bl)
+		return gray, gray, gray
+	}
+
+	r, g, b = hslToRGB(h, 1, 0.5)
+	scale := func(v float64) float64 {
+		return v*(1-w-bl) + w
+	}
+	return scale(r), scale(g), scale(b)
+}
+
+// Converts sRGB to HSL, saturation and lightness going from 0 to 1.
+func rgbToHSL(r, g, b float64) (h, s, l float64) {
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l = (max + min) / 2
+
+	d := max - min
+	if d == 0 {
+		return 0, 0, l
+	}
+
+	s = d / (1 - math.Abs(2*l-1))
+	switch max {
+	case r:
+		h = math.Mod((g-b)/d+6, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+
+	return h * 60, s, l
+}
+
+// Converts Oklab to CIELAB relative to D50.
+func oklabToLab(l, a, b float64) (float64, float64, float64) {
+	lc := l + 0.3963377774*a + 0.2158037573*b
+	mc := l - 0.1055613458*a - 0.0638541728*b
+	sc := l - 0.0894841775*a - 1.2914855480*b
+	lc, mc, sc = lc*lc*lc, mc*mc*mc, sc*sc*sc
+
+	r := 4.0767416621*lc - 3.3077115913*mc + 0.2309699292*sc
+	g := -1.2684380046*lc + 2.6097574011*mc - 0.3413193965*sc
+	bl := -0.0041960863*lc - 0.7034186147*mc + 1.7076147010*sc
+
+	return xyzToLab(d65ToD50(linearRGBToXYZ(r, g, bl)))
+}
+
+// Converts CIELAB relative to D50 to Oklab.
+func labToOklab(l, a, b float64) (float64, float64, float64) {
+	r, g, bl := xyzToLinearRGB(d50ToD65(labToXYZ(l, a, b)))
+
+	lc := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*bl)
+	mc := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*bl)
+	sc := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*bl)
+
+	return 0.2104542553*lc + 0.7936177850*mc - 0.0040720468*sc,
+		1.9779984951*lc - 2.4285922050*mc + 0.4505937099*sc,
+		0.0259040371*lc + 0.7827717662*mc - 0.8086757660*sc
+}
+
+// CSSFormat is a CSS notation a color can be formatted in.
+type CSSFormat int
+
+const (
+	CSSHex   CSSFormat = iota // #FF8000
+	CSSRGB                    // rgb(255 128 0)
+	CSSHSL                    // hsl(30.1 100% 50%)
+	CSSLab                    // lab(67.05 42.83 74.03)
+	CSSLCH                    // lch(67.05 85.53 59.95)
+	CSSOklab                  // oklab(0.7327 0.0934 0.1497)
+	CSSOklch                  // oklch(0.7327 0.1765 58.04)
+)
+
+// Returns the color as a #RRGGBB hex code, converted to sRGB.
+func (color *Color) Hex() (string, error) {
+	return color.CSS(CSSHex, 0)
+}
+
+// Returns the color in the given CSS notation, with numbers rounded to
+// precision decimals and trailing zeros dropped. Colors are converted as
+// needed, see ConvertWith. The sRGB based notations clip colors to sRGB, the
+// others don't.
+func (color *Color) CSS(format CSSFormat, precision int) (string, error) {
+	if err := color.checkValues(); err != nil {
+		return "", err
+	}
+
+	n := func(v float64) string {
+		return formatNumber(v, precision)
+	}
+
+	switch format {
+	case CSSHex:
+		r, g, b := color.rgb(NaiveCMYK{})
+		return fmt.Sprintf("#%02X%02X%02X", to8(r), to8(g), to8(b)), nil
+	case CSSRGB:
+		r, g, b := color.rgb(NaiveCMYK{})
+		return fmt.Sprintf("rgb(%s %s %s)", n(r*255), n(g*255), n(b*255)), nil
+	case CSSHSL:
+		h, s, l := rgbToHSL(color.rgb(NaiveCMYK{}))
+		return fmt.Sprintf("hsl(%s %s%% %s%%)", n(h), n(s*100), n(l*100)), nil
+	}
+
+	l, a, b := color.lab(NaiveCMYK{})
+	switch format {
+	case CSSLab:
+		return fmt.Sprintf("lab(%s %s %s)", n(l), n(a), n(b)), nil
+	case CSSLCH:
+		c, h := cartesianToPolar(a, b)
+		return fmt.Sprintf("lch(%s %s %s)", n(l), n(c), n(h)), nil
+	case CSSOklab:
+		l, a, b = labToOklab(l, a, b)
+		return fmt.Sprintf("oklab(%s %s %s)", n(l), n(a), n(b)), nil
+	case CSSOklch:
+		l, a, b = labToOklab(l, a, b)
+		c, h := cartesianToPolar(a, b)
+		return fmt.Sprintf("oklch(%s %s %s)", n(l), n(c), n(h)), nil
+	}
+
+	return "", fmt.Errorf("ase: unknown CSS format %d", format)
+}
+
+// Formats v with at most precision decimals.
+func formatNumber(v float64, precision int) string {
+	s := strconv.FormatFloat(v, 'f', precision, 64)
+	if strings.IndexByte(s, '.') >= 0 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// Scales v from [0, 1] to an 8 bit color component.
+func to8(v float64) uint8 {
+	return uint8(clip(v)*0xff + 0.5)
+}
+
+// The CSS named colors.
+var namedColors = map[string][3]uint8{
+	"aliceblue":            {240, 248, 255},
+	"antiquewhite":         {250, 235, 215},
+	"aqua":                 {0, 255, 255},
+	"aquamarine":           {127, 255, 212},
+	"azure":                {240, 255, 255},
+	"beige":                {245, 245, 220},
+	"bisque":               {255, 228, 196},
+	"black":                {0, 0, 0},
+	"blanchedalmond":       {255, 235, 205},
+	"blue":                 {0, 0, 255},
+	"blueviolet":           {138, 43, 226},
+	"brown":                {165, 42, 42},
+	"burlywood":            {222, 184, 135},
+	"cadetblue":            {95, 158, 160},
+	"chartreuse":           {127, 255, 0},
+	"chocolate":            {210, 105, 30},
+	"coral":                {255, 127, 80},
+	"cornflowerblue":       {100, 149, 237},
+	"cornsilk":             {255, 248, 220},
+	"crimson":              {220, 20, 60},
+	"cyan":                 {0, 255, 255},
+	"darkblue":             {0, 0, 139},
+	"darkcyan":             {0, 139, 139},
+	"darkgoldenrod":        {184, 134, 11},
+	"darkgray":             {169, 169, 169},
+	"darkgreen":            {0, 100, 0},
+	"darkgrey":             {169, 169, 169},
+	"darkkhaki":            {189, 183, 107},
+	"darkmagenta":          {139, 0, 139},
+	"darkolivegreen":       {85, 107, 47},
+	"darkorange":           {255, 140, 0},
+	"darkorchid":           {153, 50, 204},
+	"darkred":              {139, 0, 0},
+	"darksalmon":           {233, 150, 122},
+	"darkseagreen":         {143, 188, 143},
+	"darkslateblue":        {72, 61, 139},
+	"darkslategray":        {47, 79, 79},
+	"darkslategrey":        {47, 79, 79},
+	"darkturquoise":        {0, 206, 209},
+	"darkviolet":           {148, 0, 211},
+	"deeppink":             {255, 20, 147},
+	"deepskyblue":          {0, 191, 255},
+	"dimgray":              {105, 105, 105},
+	"dimgrey":              {105, 105, 105},
+	"dodgerblue":           {30, 144, 255},
+	"firebrick":            {178, 34, 34},
+	"floralwhite":          {255, 250, 240},
+	"forestgreen":          {34, 139, 34},
+	"fuchsia":              {255, 0, 255},
+	"gainsboro":            {220, 220, 220},
+	"ghostwhite":           {248, 248, 255},
+	"gold":                 {255, 215, 0},
+	"goldenrod":            {218, 165, 32},
+	"gray":                 {128, 128, 128},
+	"green":                {0, 128, 0},
+	"greenyellow":          {173, 255, 47},
+	"grey":                 {128, 128, 128},
+	"honeydew":             {240, 255, 240},
+	"hotpink":              {255, 105, 180},
+	"indianred":            {205, 92, 92},
+	"indigo":               {75, 0, 130},
+	"ivory":                {255, 255, 240},
+	"khaki":                {240, 230, 140},
+	"lavender":             {230, 230, 250},
+	"lavenderblush":        {255, 240, 245},
+	"lawngreen":            {124, 252, 0},
+	"lemonchiffon":         {255, 250, 205},
+	"lightblue":            {173, 216, 230},
+	"lightcoral":           {240, 128, 128},
+	"lightcyan":            {224, 255, 255},
+	"lightgoldenrodyellow": {250, 250, 210},
+	"lightgray":            {211, 211, 211},
+	"lightgreen":           {144, 238, 144},
+	"lightgrey":            {211, 211, 211},
+	"lightpink":            {255, 182, 193},
+	"lightsalmon":          {255, 160, 122},
+	"lightseagreen":        {32, 178, 170},
+	"lightskyblue":         {135, 206, 250},
+	"lightslategray":       {119, 136, 153},
+	"lightslategrey":       {119, 136, 153},
+	"lightsteelblue":       {176, 196, 222},
+	"lightyellow":          {255, 255, 224},
+	"lime":                 {0, 255, 0},
+	"limegreen":            {50, 205, 50},
+	"linen":                {250, 240, 230},
+	"magenta":              {255, 0, 255},
+	"maroon":               {128, 0, 0},
+	"mediumaquamarine":     {102, 205, 170},
+	"mediumblue":           {0, 0, 205},
+	"mediumorchid":         {186, 85, 211},
+	"mediumpurple":         {147, 112, 219},
+	"mediumseagreen":       {60, 179, 113},
+	"mediumslateblue":      {123, 104, 238},
+	"mediumspringgreen":    {0, 250, 154},
+	"mediumturquoise":      {72, 209, 204},
+	"mediumvioletred":      {199, 21, 133},
+	"midnightblue":         {25, 25, 112},
+	"mintcream":            {245, 255, 250},
+	"mistyrose":            {255, 228, 225},
+	"moccasin":             {255, 228, 181},
+	"navajowhite":          {255, 222, 173},
+	"navy":                 {0, 0, 128},
+	"oldlace":              {253, 245, 230},
+	"olive":                {128, 128, 0},
+	"olivedrab":            {107, 142, 35},
+	"orange":               {255, 165, 0},
+	"orangered":            {255, 69, 0},
+	"orchid":               {218, 112, 214},
+	"palegoldenrod":        {238, 232, 170},
+	"palegreen":            {152, 251, 152},
+	"paleturquoise":        {175, 238, 238},
+	"palevioletred":        {219, 112, 147},
+	"papayawhip":           {255, 239, 213},
+	"peachpuff":            {255, 218, 185},
+	"peru":                 {205, 133, 63},
+	"pink":                 {255, 192, 203},
+	"plum":                 {221, 160, 221},
+	"powderblue":           {176, 224, 230},
+	"purple":               {128, 0, 128},
+	"rebeccapurple":        {102, 51, 153},
+	"red":                  {255, 0, 0},
+	"rosybrown":            {188, 143, 143},
+	"royalblue":            {65, 105, 225},
+	"saddlebrown":          {139, 69, 19},
+	"salmon":               {250, 128, 114},
+	"sandybrown":           {244, 164, 96},
+	"seagreen":             {46, 139, 87},
+	"seashell":             {255, 245, 238},
+	"sienna":               {160, 82, 45},
+	"silver":               {192, 192, 192},
+	"skyblue":              {135, 206, 235},
+	"slateblue":            {106, 90, 205},
+	"slategray":            {112, 128, 144},
+	"slategrey":            {112, 128, 144},
+	"snow":                 {255, 250, 250},
+	"springgreen":          {0, 255, 127},
+	"steelblue":            {70, 130, 180},
+	"tan":                  {210, 180, 140},
+	"teal":                 {0, 128, 128},
+	"thistle":              {216, 191, 216},
+	"tomato":               {255, 99, 71},
+	"transparent":          {0, 0, 0},
+	"turquoise":            {64, 224, 208},
+	"violet":               {238, 130, 238},
+	"wheat":                {245, 222, 179},
+	"white":                {255, 255, 255},
+	"whitesmoke":           {245, 245, 245},
+	"yellow":               {255, 255, 0},
+	"yellowgreen":          {154, 205, 50},
+}
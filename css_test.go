@@ -0,0 +1,108 @@
+package ase
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		css      string
+		model    ColorModel
+		expected []float32
+	}{
+		{"#f80", RGB, []float32{1, 0x88 / 255.0, 0}},
+		{"#FF800080", RGB, []float32{1, 0x80 / 255.0, 0}},
+		{"rgb(255, 128, 0)", RGB, []float32{1, 128 / 255.0, 0}},
+		{"rgba(255,128,0,0.5)", RGB, []float32{1, 128 / 255.0, 0}},
+		{"rgb(100% 50% 0% / 50%)", RGB, []float32{1, 0.5, 0}},
+		{"rgb(300 -10 none)", RGB, []float32{1, 0, 0}},
+		{"hsl(120deg 100% 25%)", RGB, []float32{0, 0.5, 0}},
+		{"hsla(0.5turn, 100%, 50%, 1)", RGB, []float32{0, 1, 1}},
+		{"hwb(0 0% 0%)", RGB, []float32{1, 0, 0}},
+		{"hwb(200 60% 60%)", RGB, []float32{0.5, 0.5, 0.5}},
+		{"RebeccaPurple", RGB, []float32{102 / 255.0, 51 / 255.0, 153 / 255.0}},
+		{"color(srgb 1 0.5 0)", RGB, []float32{1, 0.5, 0}},
+		{"color(srgb-linear 0.2140 0.2140 0.2140)", RGB, []float32{0.5, 0.5, 0.5}},
+		{"lab(50% 40 -20)", LAB, []float32{0.5, 40, -20}},
+		{"lab(50 100% -100%)", LAB, []float32{0.5, 125, -125}},
+		{"lch(50 40 90)", LAB, []float32{0.5, 0, 40}},
+		{"oklch(62.8% 0.2577 29.23)", LAB, []float32{0.5429, 80.8, 69.89}},
+		{"oklab(1 0 0)", LAB, []float32{1, 0, 0}},
+		{"color(xyz-d50 0.96422 1 0.82521)", LAB, []float32{1, 0, 0}},
+		{"color(xyz 0.95047 1 1.08883)", LAB, []float32{1, 0, 0}},
+		{"color(display-p3 1 1 1)", LAB, []float32{1, 0, 0}},
+	}
+
+	for _, test := range tests {
+		c, err := ParseColor(test.css)
+		if err != nil {
+			t.Error(test.css, err)
+			continue
+		}
+		if c.Model != test.model || c.Type != Normal || !closeTo(c.Values, test.expected, 0.05) {
+			t.Errorf("%s: expected %s %v, got %s %v", test.css, test.model, test.expected, c.Model, c.Values)
+		}
+		if err = c.Validate(); err != nil {
+			t.Error(test.css, err)
+		}
+	}
+
+	// Display P3 red lies outside sRGB, which LAB keeps.
+	p3, _ := ParseColor("color(display-p3 1 0 0)")
+	if p3.Model != LAB || p3.Values[1] < 85 {
+		t.Error("expected display-p3 red to be redder than sRGB red, got", p3.Values)
+	}
+
+	for _, s := range []string{"#12", "#ggg", "#112233zz", "#123g", "chartreuseish", "rgb(1 2)", "hsl(10% 5% 5%)", "color(rec2020 1 0 0)", "cmyk(0 0 0 1)", "rgb(nan 0 0)", "rgb(0 inf 0 / 1)", "hsl(infinity 50% 50%)", "lab(50 NaN 0)"} {
+		if _, err := ParseColor(s); !errors.Is(err, ErrInvalidCSSColor) {
+			t.Error(s, "expected", ErrInvalidCSSColor, "got", err)
+		}
+	}
+}
+
+func TestFormatCSS(t *testing.T) {
+	red := Color{Model: RGB, Values: []float32{1, 0, 0}}
+	magenta := Color{Model: CMYK, Values: []float32{0, 1, 0, 0}}
+	gray := Color{Model: Gray, Values: []float32{0.5}}
+
+	tests := []struct {
+		color     Color
+		format    CSSFormat
+		precision int
+		expected  string
+	}{
+		{red, CSSHex, 0, "#FF0000"},
+		{red, CSSRGB, 0, "rgb(255 0 0)"},
+		{red, CSSHSL, 1, "hsl(0 100% 50%)"},
+		{red, CSSLab, 2, "lab(54.29 80.81 69.89)"},
+		{red, CSSLCH, 1, "lch(54.3 106.8 40.9)"},
+		{red, CSSOklab, 3, "oklab(0.628 0.225 0.126)"},
+		{red, CSSOklch, 2, "oklch(0.63 0.26 29.23)"},
+		{magenta, CSSHex, 0, "#FF00FF"},
+		{magenta, CSSHSL, 0, "hsl(300 100% 50%)"},
+		{gray, CSSRGB, 2, "rgb(127.5 127.5 127.5)"},
+		{gray, CSSHex, 0, "#808080"},
+		{Color{Model: LAB, Values: []float32{1, 0, 0}}, CSSLab, 3, "lab(100 0 0)"},
+	}
+
+	for _, test := range tests {
+		s, err := test.color.CSS(test.format, test.precision)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if s != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, s)
+		}
+
+		// Whatever we write, we can read back.
+		if _, err = ParseColor(s); err != nil {
+			t.Error(s, err)
+		}
+	}
+
+	if _, err := (&Color{Model: RGB}).Hex(); !errors.Is(err, ErrValueCount) {
+		t.Error("expected", ErrValueCount, "got", err)
+	}
+}
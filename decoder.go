@@ -0,0 +1,174 @@
+package ase
+
+import (
+	"bytes"
+	"io"
+)
+
+// A Token is an item read by a Decoder: a GroupStart, a GroupEnd, a Color or,
+// when decoding leniently, a RawBlock.
+type Token interface{}
+
+// GroupStart opens a group. The colors up to the matching GroupEnd belong to it.
+type GroupStart struct {
+	Name string
+
+	// Raw is set when decoding leniently and the block is malformed. It holds
+	// the block as read, along with the error.
+	Raw *RawBlock
+}
+
+// GroupEnd closes the group opened by the last GroupStart.
+type GroupEnd struct {
+	// Raw is set when decoding leniently and the block is malformed, the
+	// same way as GroupStart.Raw.
+	Raw *RawBlock
+}
+
+// A Decoder reads an ASE input one block at a time, without holding the whole
+// document in memory.
+//
+//	d := ase.NewDecoder(r)
+//	for d.Next() {
+//		switch tok := d.Token().(type) {
+//		case ase.GroupStart:
+//		case ase.Color:
+//		case ase.GroupEnd:
+//		}
+//	}
+//	if err := d.Err(); err != nil {
+//		...
+//	}
+type Decoder struct {
+	DecoderOptions
+
+	r      io.Reader
+	header ASE // signature, version and numBlocks only
+	read   bool
+	index  int
+	token  Token
+	err    error
+}
+
+// Returns a new Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Advances to the next token, which is then available through Token.
+// Returns false at the end of the input or on the first error.
+func (d *Decoder) Next() bool {
+	d.token = nil
+	if d.err != nil {
+		return false
+	}
+
+	//	the header is read along with the first block
+	if !d.read {
+		d.read = true
+		if d.err = d.readHeader(); d.err != nil {
+			return false
+		}
+	}
+
+	if d.index >= int(d.header.numBlocks) {
+		return false
+	}
+
+	d.token, d.err = d.readToken()
+	d.index++
+
+	return d.err == nil
+}
+
+// Returns the token read by the last call to Next.
+func (d *Decoder) Token() Token {
+	return d.token
+}
+
+// Returns the first error encountered by Next, if any.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Returns the input's version. It is only available once Next has been called.
+func (d *Decoder) FileVersion() FileVersion {
+	return d.header.FileVersion
+}
+
+// Decodes the input's signature, version and number of blocks.
+func (d *Decoder) readHeader() (err error) {
+	if err = d.header.readSignature(d.r); err != nil {
+		return
+	}
+	if err = d.header.readVersion(d.r, d.Version); err != nil {
+		return
+	}
+	return d.header.readNumBlocks(d.r)
+}
+
+// Decodes the next block into a token.
+func (d *Decoder) readToken() (tok Token, err error) {
+	//	new block
+	b := block{}
+
+	//	decode the block container
+	if err = b.Read(d.r); err != nil {
+		return
+	}
+
+	//	unknown block types are only tolerated in lenient mode
+	known := b.Type == colorEntry || b.Type == groupStart || b.Type == groupEnd
+	if !known && !d.Lenient {
+		return nil, ErrInvalidBlockType
+	}
+
+	//	read the whole payload up front so decoding a block can never
+	//	run into the next one
+	payload, err := b.readPayload(d.r)
+	if err != nil {
+		return
+	}
+	br := bytes.NewReader(payload)
+
+	//	switch on block type
+	switch b.Type {
+	case colorEntry:
+		c := Color{}
+		if err = blockError(d.index, b, br, c.read(br)); err != nil {
+			if !d.Lenient {
+				return
+			}
+
+			//	hand back the malformed color as is and carry on with the next block
+			return RawBlock{Type: b.Type, Data: payload, Err: err}, nil
+		}
+
+		return c, nil
+	case groupStart:
+		//	in lenient mode a malformed group still opens, with whatever name
+		//	could be read, and keeps the block to write it back as is
+		g := Group{}
+		if err = blockError(d.index, b, br, g.read(br)); err != nil {
+			if !d.Lenient {
+				return
+			}
+			return GroupStart{Name: g.Name, Raw: &RawBlock{Type: b.Type, Data: payload, Err: err}}, nil
+		}
+
+		return GroupStart{Name: g.Name}, nil
+	case groupEnd:
+		//	a group end carries no payload
+		if err = blockError(d.index, b, br, nil); err != nil {
+			if !d.Lenient {
+				return
+			}
+			return GroupEnd{Raw: &RawBlock{Type: b.Type, Data: payload, Err: err}}, nil
+		}
+
+		return GroupEnd{}, nil
+	default:
+		//	keep the unknown block verbatim
+		return RawBlock{Type: b.Type, Data: payload}, nil
+	}
+}
@@ -0,0 +1,71 @@
+package ase
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	f, err := os.Open("samples/test.ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	d := NewDecoder(f)
+
+	var got []string
+	for d.Next() {
+		switch tok := d.Token().(type) {
+		case GroupStart:
+			got = append(got, "start:"+tok.Name)
+		case Color:
+			got = append(got, tok.Name)
+		case GroupEnd:
+			got = append(got, "end")
+		default:
+			t.Errorf("unexpected token %#v", tok)
+		}
+	}
+	if err = d.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"RGB", "Grayscale", "cmyk", "LAB", "PANTONE P 1-8 C",
+		"start:A Color Group", "Red", "Green", "Blue", "end",
+	}
+	if len(got) != len(expected) {
+		t.Fatal("expected tokens", expected, "got", got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Error("expected token", expected[i], "got", got[i])
+		}
+	}
+
+	if d.FileVersion() != Version1 {
+		t.Error("expected version 1.0, got", d.FileVersion())
+	}
+
+	// Once exhausted, the decoder stays exhausted.
+	if d.Next() || d.Token() != nil {
+		t.Error("expected no more tokens")
+	}
+}
+
+func TestDecoderInvalidFile(t *testing.T) {
+	f, err := os.Open("decoder.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	d := NewDecoder(f)
+	if d.Next() {
+		t.Error("expected no tokens")
+	}
+	if d.Err() != ErrInvalidFile {
+		t.Error("expected", ErrInvalidFile, "got", d.Err())
+	}
+}
@@ -0,0 +1,202 @@
+package ase
+
+import (
+	"math"
+)
+
+// The Delta E functions measure how different two colors look. Both colors are
+// converted to CIELAB relative to D50 first, the same way ToLab does, so they
+// can be in any model. A difference of about 1 is the smallest most people
+// notice side by side, with DeltaE2000 being the closest to perception.
+
+// Returns the CIE 1976 difference between two colors: their euclidean
+// distance in LAB.
+func DeltaE76(c1, c2 Color) (float64, error) {
+	l1, a1, b1, err := labOf(&c1)
+	if err != nil {
+		return 0, err
+	}
+	l2, a2, b2, err := labOf(&c2)
+	if err != nil {
+		return 0, err
+	}
+
+	return deltaE76(l1, a1, b1, l2, a2, b2), nil
+}
+
+// Returns the CIE 1994 difference of sample from reference, with the graphic
+// arts weights. It isn't symmetric.
+func DeltaE94(reference, sample Color) (float64, error) {
+	l1, a1, b1, err := labOf(&reference)
+	if err != nil {
+		return 0, err
+	}
+	l2, a2, b2, err := labOf(&sample)
+	if err != nil {
+		return 0, err
+	}
+
+	return deltaE94(l1, a1, b1, l2, a2, b2), nil
+}
+
+// Returns the CIEDE2000 difference between two colors, with the parametric
+// weights set to 1.
+func DeltaE2000(c1, c2 Color) (float64, error) {
+	l1, a1, b1, err := labOf(&c1)
+	if err != nil {
+		return 0, err
+	}
+	l2, a2, b2, err := labOf(&c2)
+	if err != nil {
+		return 0, err
+	}
+
+	return deltaE2000(l1, a1, b1, l2, a2, b2), nil
+}
+
+// Returns the CMC l:c difference of sample from reference. The usual weights
+// are 2:1 for acceptability and 1:1 for perceptibility. It isn't symmetric.
+func DeltaECMC(reference, sample Color, l, c float64) (float64, error) {
+	l1, a1, b1, err := labOf(&reference)
+	if err != nil {
+		return 0, err
+	}
+	l2, a2, b2, err := labOf(&sample)
+	if err != nil {
+		return 0, err
+	}
+
+	return deltaECMC(l1, a1, b1, l2, a2, b2, l, c), nil
+}
+
+// Returns the color as CIELAB, lightness going from 0 to 100.
+func labOf(color *Color) (l, a, b float64, err error) {
+	if err = color.checkValues(); err != nil {
+		return
+	}
+	l, a, b = color.lab(NaiveCMYK{})
+	return
+}
+
+func deltaE76(l1, a1, b1, l2, a2, b2 float64) float64 {
+	return math.Sqrt(sq(l1-l2) + sq(a1-a2) + sq(b1-b2))
+}
+
+func deltaE94(l1, a1, b1, l2, a2, b2 float64) float64 {
+	const (
+		k1 = 0.045
+		k2 = 0.015
+	)
+
+	c1, c2 := math.Hypot(a1, b1), math.Hypot(a2, b2)
+	dL, dC := l1-l2, c1-c2
+
+	//	what's left of the distance once lightness and chroma are accounted for
+	dH2 := math.Max(0, sq(a1-a2)+sq(b1-b2)-sq(dC))
+
+	sC := 1 + k1*c1
+	sH := 1 + k2*c1
+
+	return math.Sqrt(sq(dL) + sq(dC/sC) + dH2/sq(sH))
+}
+
+// See "The CIEDE2000 Color-Difference Formula: Implementation Notes,
+// Supplementary Test Data, and Mathematical Observations" by Sharma, Wu and
+// Dalal.
+func deltaE2000(l1, a1, b1, l2, a2, b2 float64) float64 {
+	//	stretch a so neutral colors get their chroma right
+	cBar := (math.Hypot(a1, b1) + math.Hypot(a2, b2)) / 2
+	g := 0.5 * (1 - math.Sqrt(math.Pow(cBar, 7)/(math.Pow(cBar, 7)+math.Pow(25, 7))))
+	a1, a2 = a1*(1+g), a2*(1+g)
+
+	c1, c2 := math.Hypot(a1, b1), math.Hypot(a2, b2)
+	h1, h2 := hueAngle(a1, b1), hueAngle(a2, b2)
+
+	dL := l2 - l1
+	dC := c2 - c1
+
+	//	the hue difference, the short way round, and none without chroma
+	var dh float64
+	if c1*c2 != 0 {
+		dh = h2 - h1
+		if dh > 180 {
+			dh -= 360
+		} else if dh < -180 {
+			dh += 360
+		}
+	}
+	dH := 2 * math.Sqrt(c1*c2) * math.Sin(radians(dh/2))
+
+	lBar := (l1 + l2) / 2
+	cBar = (c1 + c2) / 2
+
+	//	the mean hue, also the short way round
+	hBar := h1 + h2
+	if c1*c2 != 0 {
+		if math.Abs(h1-h2) <= 180 {
+			hBar /= 2
+		} else if hBar < 360 {
+			hBar = (hBar + 360) / 2
+		} else {
+			hBar = (hBar - 360) / 2
+		}
+	}
+
+	t := 1 - 0.17*math.Cos(radians(hBar-30)) +
+		0.24*math.Cos(radians(2*hBar)) +
+		0.32*math.Cos(radians(3*hBar+6)) -
+		0.20*math.Cos(radians(4*hBar-63))
+
+	sL := 1 + 0.015*sq(lBar-50)/math.Sqrt(20+sq(lBar-50))
+	sC := 1 + 0.045*cBar
+	sH := 1 + 0.015*cBar*t
+
+	//	blue needs rotating
+	dTheta := 30 * math.Exp(-sq((hBar-275)/25))
+	rC := 2 * math.Sqrt(math.Pow(cBar, 7)/(math.Pow(cBar, 7)+math.Pow(25, 7)))
+	rT := -rC * math.Sin(radians(2*dTheta))
+
+	return math.Sqrt(sq(dL/sL) + sq(dC/sC) + sq(dH/sH) + rT*(dC/sC)*(dH/sH))
+}
+
+func deltaECMC(l1, a1, b1, l2, a2, b2, l, c float64) float64 {
+	c1, c2 := math.Hypot(a1, b1), math.Hypot(a2, b2)
+	dL, dC := l1-l2, c1-c2
+	dH2 := math.Max(0, sq(a1-a2)+sq(b1-b2)-sq(dC))
+
+	h1 := hueAngle(a1, b1)
+	t := 0.36 + math.Abs(0.4*math.Cos(radians(h1+35)))
+	if h1 >= 164 && h1 <= 345 {
+		t = 0.56 + math.Abs(0.2*math.Cos(radians(h1+168)))
+	}
+	f := math.Sqrt(math.Pow(c1, 4) / (math.Pow(c1, 4) + 1900))
+
+	sL := 0.511
+	if l1 >= 16 {
+		sL = 0.040975 * l1 / (1 + 0.01765*l1)
+	}
+	sC := 0.0638*c1/(1+0.0131*c1) + 0.638
+	sH := sC * (f*t + 1 - f)
+
+	return math.Sqrt(sq(dL/(l*sL)) + sq(dC/(c*sC)) + dH2/sq(sH))
+}
+
+// Returns the hue of a LAB color in degrees, from 0 to 360.
+func hueAngle(a, b float64) float64 {
+	if a == 0 && b == 0 {
+		return 0
+	}
+	h := math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func sq(v float64) float64 {
+	return v * v
+}
@@ -0,0 +1,119 @@
+package ase
+
+import (
+	"math"
+	"testing"
+)
+
+// The test data set of "The CIEDE2000 Color-Difference Formula: Implementation
+// Notes, Supplementary Test Data, and Mathematical Observations", Sharma, Wu
+// and Dalal, 2005: L, a and b of both colors, then their difference.
+var ciede2000Pairs = [][7]float64{
+	{50.0000, 2.6772, -79.7751, 50.0000, 0.0000, -82.7485, 2.0425},
+	{50.0000, 3.1571, -77.2803, 50.0000, 0.0000, -82.7485, 2.8615},
+	{50.0000, 2.8361, -74.0200, 50.0000, 0.0000, -82.7485, 3.4412},
+	{50.0000, -1.3802, -84.2814, 50.0000, 0.0000, -82.7485, 1.0000},
+	{50.0000, -1.1848, -84.8006, 50.0000, 0.0000, -82.7485, 1.0000},
+	{50.0000, -0.9009, -85.5211, 50.0000, 0.0000, -82.7485, 1.0000},
+	{50.0000, 0.0000, 0.0000, 50.0000, -1.0000, 2.0000, 2.3669},
+	{50.0000, -1.0000, 2.0000, 50.0000, 0.0000, 0.0000, 2.3669},
+	{50.0000, 2.4900, -0.0010, 50.0000, -2.4900, 0.0009, 7.1792},
+	{50.0000, 2.4900, -0.0010, 50.0000, -2.4900, 0.0010, 7.1792},
+	{50.0000, 2.4900, -0.0010, 50.0000, -2.4900, 0.0011, 7.2195},
+	{50.0000, 2.4900, -0.0010, 50.0000, -2.4900, 0.0012, 7.2195},
+	{50.0000, -0.0010, 2.4900, 50.0000, 0.0009, -2.4900, 4.8045},
+	{50.0000, -0.0010, 2.4900, 50.0000, 0.0010, -2.4900, 4.8045},
+	{50.0000, -0.0010, 2.4900, 50.0000, 0.0011, -2.4900, 4.7461},
+	{50.0000, 2.5000, 0.0000, 50.0000, 0.0000, -2.5000, 4.3065},
+	{50.0000, 2.5000, 0.0000, 73.0000, 25.0000, -18.0000, 27.1492},
+	{50.0000, 2.5000, 0.0000, 61.0000, -5.0000, 29.0000, 22.8977},
+	{50.0000, 2.5000, 0.0000, 56.0000, -27.0000, -3.0000, 31.9030},
+	{50.0000, 2.5000, 0.0000, 58.0000, 24.0000, 15.0000, 19.4535},
+	{50.0000, 2.5000, 0.0000, 50.0000, 3.1736, 0.5854, 1.0000},
+	{50.0000, 2.5000, 0.0000, 50.0000, 3.2972, 0.0000, 1.0000},
+	{50.0000, 2.5000, 0.0000, 50.0000, 1.8634, 0.5757, 1.0000},
+	{50.0000, 2.5000, 0.0000, 50.0000, 3.2592, 0.3350, 1.0000},
+	{60.2574, -34.0099, 36.2677, 60.4626, -34.1751, 39.4387, 1.2644},
+	{63.0109, -31.0961, -5.8663, 62.8187, -29.7946, -4.0864, 1.2630},
+	{61.2901, 3.7196, -5.3901, 61.4292, 2.2480, -4.9620, 1.8731},
+	{35.0831, -44.1164, 3.7933, 35.0232, -40.0716, 1.5901, 1.8645},
+	{22.7233, 20.0904, -46.6940, 23.0331, 14.9730, -42.5619, 2.0373},
+	{36.4612, 47.8580, 18.3852, 36.2715, 50.5065, 21.2231, 1.4146},
+	{90.8027, -2.0831, 1.4410, 91.1528, -1.6435, 0.0447, 1.4441},
+	{90.9257, -0.5406, -0.9208, 88.6381, -0.8985, -0.7239, 1.5381},
+	{6.7747, -0.2908, -2.4247, 5.8714, -0.0985, -2.2286, 0.6377},
+	{2.0776, 0.0795, -1.1350, 0.9033, -0.0636, -0.5514, 0.9082},
+}
+
+func TestDeltaE2000(t *testing.T) {
+	for i, p := range ciede2000Pairs {
+		d := deltaE2000(p[0], p[1], p[2], p[3], p[4], p[5])
+		if math.Abs(d-p[6]) > 0.00005 {
+			t.Errorf("pair %d: expected %.4f, got %.4f", i+1, p[6], d)
+		}
+
+		// The formula is symmetric.
+		if r := deltaE2000(p[3], p[4], p[5], p[0], p[1], p[2]); math.Abs(r-d) > 1e-9 {
+			t.Errorf("pair %d: expected %.4f both ways, got %.4f", i+1, d, r)
+		}
+	}
+}
+
+func TestDeltaE(t *testing.T) {
+	lab := func(l, a, b float32) Color {
+		return Color{Name: "lab", Model: LAB, Values: []float32{l / 100, a, b}, Type: Normal}
+	}
+
+	// Through Color, float32 values cost some precision.
+	p := ciede2000Pairs[24]
+	d, err := DeltaE2000(lab(float32(p[0]), float32(p[1]), float32(p[2])), lab(float32(p[3]), float32(p[4]), float32(p[5])))
+	if err != nil || math.Abs(d-p[6]) > 0.001 {
+		t.Error("expected", p[6], "got", d, err)
+	}
+
+	// Lightness alone counts the same for 76 and 94, and halved for CMC 2:1
+	// around L 50.
+	gray1, gray2 := lab(50, 0, 0), lab(60, 0, 0)
+	for _, test := range []struct {
+		name     string
+		fn       func(c1, c2 Color) (float64, error)
+		expected float64
+	}{
+		{"76", DeltaE76, 10},
+		{"94", DeltaE94, 10},
+		{"CMC", func(c1, c2 Color) (float64, error) { return DeltaECMC(c1, c2, 2, 1) }, 10 / (2 * 0.040975 * 50 / (1 + 0.01765*50))},
+	} {
+		d, err := test.fn(gray1, gray2)
+		if err != nil || math.Abs(d-test.expected) > 0.001 {
+			t.Error(test.name, "expected", test.expected, "got", d, err)
+		}
+	}
+
+	// Pinned values for a chromatic pair, 94 and CMC not being symmetric.
+	red, orange := lab(50, 60, 40), lab(55, 50, 55)
+	for _, test := range []struct {
+		name     string
+		fn       func(c1, c2 Color) (float64, error)
+		expected [2]float64
+	}{
+		{"76", DeltaE76, [2]float64{18.7083, 18.7083}},
+		{"94", DeltaE94, [2]float64{9.9567, 9.8396}},
+		{"2000", DeltaE2000, [2]float64{11.2250, 11.2250}},
+		{"CMC", func(c1, c2 Color) (float64, error) { return DeltaECMC(c1, c2, 1, 1) }, [2]float64{12.6705, 14.9975}},
+	} {
+		d1, _ := test.fn(red, orange)
+		d2, _ := test.fn(orange, red)
+		if math.Abs(d1-test.expected[0]) > 0.0001 || math.Abs(d2-test.expected[1]) > 0.0001 {
+			t.Errorf("%s: expected %.4f and %.4f, got %.4f and %.4f", test.name, test.expected[0], test.expected[1], d1, d2)
+		}
+	}
+
+	// Any model works, broken colors don't.
+	white := Color{Name: "white", Model: CMYK, Values: []float32{0, 0, 0, 0}, Type: Normal}
+	if d, err := DeltaE76(white, lab(100, 0, 0)); err != nil || d > 0.01 {
+		t.Error("expected CMYK white to be LAB white, got", d, err)
+	}
+	if _, err := DeltaE2000(white, Color{Model: RGB}); err == nil {
+		t.Error("expected an error for a color without values")
+	}
+}
@@ -0,0 +1,188 @@
+package ase
+
+import (
+	"fmt"
+)
+
+// ChangeKind says how a color changed between two palettes.
+type ChangeKind string
+
+const (
+	Added     ChangeKind = "added"
+	Removed   ChangeKind = "removed"
+	Renamed   ChangeKind = "renamed"   // same group and values, new name
+	Recolored ChangeKind = "recolored" // same name, new model or values
+	Retyped   ChangeKind = "retyped"   // same name, new type
+	Moved     ChangeKind = "moved"     // same name, new group
+)
+
+// A Change is one difference between two palettes, as reported by Diff.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+
+	// Name and Group locate the color in the new palette, or in the old one
+	// when it was removed. Loose colors have no group.
+	Name  string `json:"name"`
+	Group string `json:"group,omitempty"`
+
+	// OldName and OldGroup locate the color in the old palette when it was
+	// renamed or moved.
+	OldName  string `json:"oldName,omitempty"`
+	OldGroup string `json:"oldGroup,omitempty"`
+
+	// Old and New are the color before and after the change. Old is nil
+	// for added colors and New for removed ones.
+	Old *Color `json:"old,omitempty"`
+	New *Color `json:"new,omitempty"`
+
+	// DeltaE is the distance between the old and new colors when
+	// recolored, see DeltaE2000. It's 0 when either can't be converted to
+	// LAB.
+	DeltaE float64 `json:"deltaE,omitempty"`
+}
+
+// Describes the change on one line, such as "recolored Brand/Red ΔE 2.35".
+func (change Change) String() string {
+	path := ColorPath(change.Group, change.Name)
+
+	switch change.Kind {
+	case Renamed:
+		return fmt.Sprintf("%s %s -> %s", change.Kind, ColorPath(change.OldGroup, change.OldName), path)
+	case Moved:
+		return fmt.Sprintf("%s %s -> %s", change.Kind, ColorPath(change.OldGroup, change.Name), path)
+	case Recolored:
+		return fmt.Sprintf("%s %s ΔE %.2f", change.Kind, path, change.DeltaE)
+	case Retyped:
+		return fmt.Sprintf("%s %s %s -> %s", change.Kind, path, change.Old.Type, change.New.Type)
+	}
+	return fmt.Sprintf("%s %s", change.Kind, path)
+}
+
+// Returns "group/name", the way Change and ContrastPair name colors, or just
+// name for loose colors.
+func ColorPath(group, name string) string {
+	if group == "" {
+		return name
+	}
+	return group + "/" + name
+}
+
+// A color of a palette being diffed, along with the name of its group.
+type diffColor struct {
+	group   string
+	color   *Color
+	matched bool
+}
+
+// Returns the changes that turn palette a into palette b.
+//
+// Colors are matched by group and name first. Colors left over are then
+// matched by name alone, which makes them moved, and finally by model and
+// values within a group, which makes them renamed. Matched colors are
+// reported as recolored and retyped when their values and type differ, and
+// anything left is removed from a or added to b. Duplicate names are matched
+// in order.
+//
+// Changes are listed in the order of a's colors, followed by the colors
+// added to b.
+func Diff(a, b ASE) (changes []Change) {
+	olds, news := diffColors(&a), diffColors(&b)
+
+	//	the color of b each color of a was matched with
+	pairs := make([]*diffColor, len(olds))
+
+	match := func(key func(c *diffColor) string) {
+		index := map[string][]*diffColor{}
+		for _, n := range news {
+			if !n.matched {
+				k := key(n)
+				index[k] = append(index[k], n)
+			}
+		}
+		for i, o := range olds {
+			k := key(o)
+			if o.matched || len(index[k]) == 0 {
+				continue
+			}
+			n := index[k][0]
+			index[k] = index[k][1:]
+			o.matched, n.matched = true, true
+			pairs[i] = n
+		}
+	}
+
+	match(func(c *diffColor) string { return c.group + "\x00" + c.color.Name })
+	match(func(c *diffColor) string { return c.color.Name })
+	match(func(c *diffColor) string { return c.group + "\x00" + colorKey(c.color) })
+
+	for i, o := range olds {
+		n := pairs[i]
+		if n == nil {
+			changes = append(changes, Change{Kind: Removed, Name: o.color.Name, Group: o.group, Old: copyColor(o.color)})
+			continue
+		}
+
+		change := Change{Name: n.color.Name, Group: n.group, Old: copyColor(o.color), New: copyColor(n.color)}
+		if o.color.Name != n.color.Name {
+			change.Kind, change.OldName, change.OldGroup = Renamed, o.color.Name, o.group
+			changes = append(changes, change)
+			continue
+		}
+		if o.group != n.group {
+			change.Kind, change.OldGroup = Moved, o.group
+			changes = append(changes, change)
+			change.OldGroup = ""
+		}
+		if colorKey(o.color) != colorKey(n.color) {
+			change.Kind, change.DeltaE = Recolored, deltaE(o.color, n.color)
+			changes = append(changes, change)
+			change.DeltaE = 0
+		}
+		if o.color.Type != n.color.Type {
+			change.Kind = Retyped
+			changes = append(changes, change)
+		}
+	}
+
+	for _, n := range news {
+		if !n.matched {
+			changes = append(changes, Change{Kind: Added, Name: n.color.Name, Group: n.group, New: copyColor(n.color)})
+		}
+	}
+
+	return
+}
+
+// Lists every color Encode would write along with its group's name.
+func diffColors(ase *ASE) (colors []*diffColor) {
+	ase.eachColor(func(group *Group, color *Color) {
+		c := &diffColor{color: color}
+		if group != nil {
+			c.group = group.Name
+		}
+		colors = append(colors, c)
+	})
+	return
+}
+
+// Returns a key that is the same for colors with the same model and values.
+func colorKey(color *Color) string {
+	return fmt.Sprint(color.Model, color.Values)
+}
+
+// Returns a copy of color that doesn't share its values.
+func copyColor(color *Color) *Color {
+	c := *color
+	c.Values = append([]float32(nil), color.Values...)
+	return &c
+}
+
+// Returns the CIEDE2000 distance between two colors, or 0 if either can't be
+// converted to LAB.
+func deltaE(c1, c2 *Color) float64 {
+	d, err := DeltaE2000(*c1, *c2)
+	if err != nil {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,94 @@
+package ase
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := ASE{
+		Colors: []Color{
+			{Name: "White", Model: RGB, Values: []float32{1, 1, 1}, Type: Normal},
+			{Name: "Gone", Model: Gray, Values: []float32{0.5}, Type: Normal},
+		},
+		Groups: []Group{
+			{Name: "Brand", Colors: []Color{
+				{Name: "Red", Model: RGB, Values: []float32{1, 0, 0}, Type: Global},
+				{Name: "Blue", Model: RGB, Values: []float32{0, 0, 1}, Type: Global},
+				{Name: "Green", Model: RGB, Values: []float32{0, 1, 0}, Type: Global},
+				{Name: "Ink", Model: CMYK, Values: []float32{0, 0, 0, 1}, Type: Spot},
+			}},
+		},
+	}
+
+	b := ASE{
+		Colors: []Color{
+			{Name: "White", Model: RGB, Values: []float32{1, 1, 1}, Type: Normal},
+			{Name: "Ink", Model: CMYK, Values: []float32{0, 0, 0, 1}, Type: Spot},
+		},
+		Groups: []Group{
+			{Name: "Brand", Colors: []Color{
+				{Name: "Red", Model: RGB, Values: []float32{0.9, 0, 0}, Type: Spot},
+				{Name: "Navy", Model: RGB, Values: []float32{0, 0, 1}, Type: Global},
+				{Name: "Green", Model: RGB, Values: []float32{0, 1, 0}, Type: Global},
+				{Name: "New", Model: LAB, Values: []float32{0.5, 0, 0}, Type: Normal},
+			}},
+		},
+	}
+
+	changes := Diff(a, b)
+
+	expected := []string{
+		"removed Gone",
+		"recolored Brand/Red ΔE 5.53",
+		"retyped Brand/Red Global -> Spot",
+		"renamed Brand/Blue -> Brand/Navy",
+		"moved Brand/Ink -> Ink",
+		"added Brand/New",
+	}
+	if len(changes) != len(expected) {
+		t.Fatal("expected", expected, "got", changes)
+	}
+	for i, change := range changes {
+		if change.String() != expected[i] {
+			t.Errorf("change %d: expected %q, got %q", i, expected[i], change.String())
+		}
+	}
+
+	if changes[0].New != nil || changes[0].Old.Name != "Gone" {
+		t.Error("expected only the old color for removed colors", changes[0])
+	}
+	if changes[5].Old != nil || changes[5].New.Name != "New" {
+		t.Error("expected only the new color for added colors", changes[5])
+	}
+
+	// Changes don't share values with the palettes.
+	a.Groups[0].Colors[0].Values[0] = 0
+	if changes[1].Old.Values[0] != 1 {
+		t.Error("expected a copy of the old color")
+	}
+
+	out, err := json.Marshal(changes[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(out), `{"kind":"renamed","name":"Navy","group":"Brand","oldName":"Blue","oldGroup":"Brand","old":{"name":"Blue"`) {
+		t.Error("unexpected JSON", string(out))
+	}
+}
+
+func TestDiffSame(t *testing.T) {
+	a, err := DecodeFile("samples/test.ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := DecodeFile("samples/test.ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changes := Diff(a, b); len(changes) != 0 {
+		t.Error("expected no changes, got", changes)
+	}
+}
@@ -0,0 +1,188 @@
+package ase
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var (
+	ErrNestedGroup   = errors.New("ase: group already open")
+	ErrNoGroup       = errors.New("ase: no open group")
+	ErrUnclosedGroup = errors.New("ase: group left open")
+	ErrEncoderClosed = errors.New("ase: encoder closed")
+)
+
+// An Encoder writes an ASE output one entry at a time.
+//
+// The header holds the number of blocks, which isn't known until Close. If the
+// output is an io.WriteSeeker, blocks are written through and the count is
+// patched in on Close. Otherwise the encoded blocks are buffered and the whole
+// output is written on Close.
+type Encoder struct {
+	EncoderOptions
+
+	w      io.Writer
+	ws     io.WriteSeeker
+	start  int64         // offset of the header when seeking
+	buf    *bytes.Buffer // encoded blocks when not seeking
+	began  bool
+	group  *Group
+	count  int32
+	closed bool
+}
+
+// Returns a new Encoder writing to w. Close must be called once all entries
+// are written.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encodes a color, inside the open group if there is one. The color is
+// validated first unless SkipValidation is set.
+func (e *Encoder) WriteColor(c Color) (err error) {
+	if !e.SkipValidation {
+		if err = c.Validate(); err != nil {
+			return
+		}
+	}
+
+	return e.writeBlock(func(w io.Writer) error {
+		return c.write(w)
+	})
+}
+
+// Opens a group named name. Groups can't be nested.
+func (e *Encoder) BeginGroup(name string) (err error) {
+	if e.group != nil {
+		return ErrNestedGroup
+	}
+
+	g := &Group{Name: name}
+	if !e.SkipValidation {
+		if err = g.Validate(); err != nil {
+			return
+		}
+	}
+	if err = e.writeBlock(g.writeStart); err != nil {
+		return
+	}
+	e.group = g
+
+	return
+}
+
+// Closes the group opened by BeginGroup.
+func (e *Encoder) EndGroup() (err error) {
+	if e.group == nil {
+		return ErrNoGroup
+	}
+
+	if err = e.writeBlock(e.group.writeBlockEnd); err != nil {
+		return
+	}
+	e.group = nil
+
+	return
+}
+
+// Completes the output by writing or patching the header's block count.
+// It doesn't close the underlying writer.
+func (e *Encoder) Close() (err error) {
+	if e.closed {
+		return ErrEncoderClosed
+	}
+	if e.group != nil {
+		return ErrUnclosedGroup
+	}
+	if err = e.begin(); err != nil {
+		return
+	}
+	e.closed = true
+
+	if e.ws == nil {
+		if err = e.writeHeader(e.w); err != nil {
+			return
+		}
+		_, err = e.buf.WriteTo(e.w)
+		return
+	}
+
+	//	jump back to the block count, right after the signature and version
+	end, err := e.ws.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return
+	}
+	if _, err = e.ws.Seek(e.start+8, io.SeekStart); err != nil {
+		return
+	}
+	if err = binary.Write(e.ws, binary.BigEndian, e.count); err != nil {
+		return
+	}
+	_, err = e.ws.Seek(end, io.SeekStart)
+
+	return
+}
+
+// Encodes one block through write and counts it. The block is encoded aside
+// and written in one call, so one that fails to encode, a color with bad
+// values under SkipValidation for instance, leaves nothing in the output.
+func (e *Encoder) writeBlock(write func(w io.Writer) error) (err error) {
+	if e.closed {
+		return ErrEncoderClosed
+	}
+	if err = e.begin(); err != nil {
+		return
+	}
+
+	var block bytes.Buffer
+	if err = write(&block); err != nil {
+		return
+	}
+
+	w := io.Writer(e.buf)
+	if e.ws != nil {
+		w = e.ws
+	}
+	if _, err = w.Write(block.Bytes()); err != nil {
+		return
+	}
+	e.count++
+
+	return
+}
+
+// Picks between seeking and buffering on first use. When seeking, the header
+// is written right away with a placeholder block count.
+func (e *Encoder) begin() (err error) {
+	if e.began {
+		return
+	}
+	e.began = true
+
+	//	not every io.WriteSeeker can actually seek, pipes for one
+	if ws, ok := e.w.(io.WriteSeeker); ok {
+		if start, err := ws.Seek(0, io.SeekCurrent); err == nil {
+			e.ws = ws
+			e.start = start
+			return e.writeHeader(ws)
+		}
+	}
+
+	e.buf = new(bytes.Buffer)
+
+	return
+}
+
+// Encodes the signature, the version and the current block count.
+func (e *Encoder) writeHeader(w io.Writer) (err error) {
+	var header ASE
+	if err = header.writeSignature(w); err != nil {
+		return
+	}
+	if err = header.writeVersion(w, e.Version); err != nil {
+		return
+	}
+	return binary.Write(w, binary.BigEndian, e.count)
+}
@@ -0,0 +1,138 @@
+package ase
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// Streams the contents of samples/test.ase through an Encoder.
+func encodeTestColors(e *Encoder) (err error) {
+	for _, c := range testColors {
+		if err = e.WriteColor(c); err != nil {
+			return
+		}
+	}
+	if err = e.BeginGroup(testGroup.Name); err != nil {
+		return
+	}
+	for _, c := range testGroup.Colors {
+		if err = e.WriteColor(c); err != nil {
+			return
+		}
+	}
+	if err = e.EndGroup(); err != nil {
+		return
+	}
+	return e.Close()
+}
+
+func TestEncoder(t *testing.T) {
+	expected, err := ioutil.ReadFile("samples/test.ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A bytes.Buffer can't seek, so the blocks get buffered.
+	b := new(bytes.Buffer)
+	if err = encodeTestColors(NewEncoder(b)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), expected) {
+		t.Error("buffered encoder output differs from samples/test.ase")
+	}
+
+	// A file can, so the block count gets patched.
+	f, err := ioutil.TempFile("", "ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err = encodeTestColors(NewEncoder(f)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Error("seeking encoder output differs from samples/test.ase")
+	}
+}
+
+// A color that fails to encode partway leaves nothing behind.
+func TestEncoderFailedColor(t *testing.T) {
+	f, err := ioutil.TempFile("", "ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	broken := testColors[0]
+	broken.Type = "global"
+
+	for _, w := range []io.ReadWriter{new(bytes.Buffer), f} {
+		e := NewEncoder(w)
+		e.SkipValidation = true
+
+		if err = e.WriteColor(testColors[0]); err != nil {
+			t.Fatal(err)
+		}
+		if err = e.WriteColor(broken); err != ErrInvalidColorType {
+			t.Error("expected", ErrInvalidColorType, "got", err)
+		}
+		if err = e.WriteColor(testColors[1]); err != nil {
+			t.Fatal(err)
+		}
+		if err = e.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if f, ok := w.(*os.File); ok {
+			if _, err = f.Seek(0, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+		}
+		decoded, err := Decode(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.NumBlocks() != 2 || len(decoded.Colors) != 2 || decoded.Colors[1].Name != testColors[1].Name {
+			t.Errorf("%T: unexpected output %d %v", w, decoded.NumBlocks(), decoded.Colors)
+		}
+	}
+}
+
+func TestEncoderGroups(t *testing.T) {
+	e := NewEncoder(new(bytes.Buffer))
+
+	if err := e.EndGroup(); err != ErrNoGroup {
+		t.Error("expected", ErrNoGroup, "got", err)
+	}
+	if err := e.BeginGroup("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.BeginGroup("b"); err != ErrNestedGroup {
+		t.Error("expected", ErrNestedGroup, "got", err)
+	}
+	if err := e.Close(); err != ErrUnclosedGroup {
+		t.Error("expected", ErrUnclosedGroup, "got", err)
+	}
+	if err := e.EndGroup(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.WriteColor(testColors[0]); err != ErrEncoderClosed {
+		t.Error("expected", ErrEncoderClosed, "got", err)
+	}
+}
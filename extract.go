@@ -0,0 +1,268 @@
+package ase
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	imagecolor "image/color"
+	"math"
+	"sort"
+)
+
+var ErrNoPixels = errors.New("ase: image has no pixels to extract colors from")
+
+// ExtractMethod is the algorithm Extract uses to pick colors.
+type ExtractMethod int
+
+const (
+	// MedianCut splits the image's colors into boxes, always cutting the
+	// longest box in half at its median. It's fast and keeps small areas of
+	// distinct colors.
+	MedianCut ExtractMethod = iota
+
+	// KMeans refines the median cut colors with k-means clustering. It's
+	// slower and favors the dominant colors.
+	KMeans
+)
+
+// ExtractOptions controls how Extract picks colors.
+type ExtractOptions struct {
+	// Colors is the most colors to extract, 8 when 0. Images with fewer
+	// distinct colors give fewer.
+	Colors int
+
+	Method ExtractMethod
+
+	// IncludeTransparent counts pixels that are less than half opaque,
+	// which are ignored by default. Their color is taken as if they were
+	// opaque.
+	IncludeTransparent bool
+
+	// HexNames names colors after their hex code, such as "#FF8000",
+	// instead of their rank, such as "Color 1".
+	HexNames bool
+
+	// Name is the name of the group. Type is the type of its colors, Normal
+	// when empty.
+	Name string
+	Type ColorType
+}
+
+// Images with more pixels than this are sampled.
+const maxExtractPixels = 1 << 18
+
+// A distinct color of an image, in LAB, and the number of pixels it covers.
+type extractPoint struct {
+	lab    [3]float64
+	weight float64
+}
+
+// Returns a group holding the main colors of img, most common first. Colors are
+// picked in LAB, so they're the ones that look the most different, and are
+// returned as RGB.
+func Extract(img image.Image, opts ExtractOptions) (group Group, err error) {
+	n := opts.Colors
+	if n == 0 {
+		n = 8
+	}
+	if n < 0 {
+		return group, fmt.Errorf("ase: can't extract %d colors", n)
+	}
+
+	points := extractPoints(img, opts.IncludeTransparent)
+	if len(points) == 0 {
+		return group, ErrNoPixels
+	}
+
+	clusters := medianCut(points, n)
+	if opts.Method == KMeans {
+		clusters = kMeans(points, clusters)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].weight > clusters[j].weight
+	})
+
+	colorType := opts.Type
+	if colorType == "" {
+		colorType = Normal
+	}
+
+	group.Name = opts.Name
+	for i, c := range clusters {
+		r, g, b := labToRGB(c.lab[0], c.lab[1], c.lab[2])
+		color := Color{
+			Name:   fmt.Sprint("Color ", i+1),
+			Model:  RGB,
+			Values: []float32{float32(clip(r)), float32(clip(g)), float32(clip(b))},
+			Type:   colorType,
+		}
+		if opts.HexNames {
+			color.Name, _ = color.Hex()
+		}
+		group.Colors = append(group.Colors, color)
+	}
+
+	return
+}
+
+// Returns the distinct 8 bit colors of img with their pixel counts, sampling
+// large images.
+func extractPoints(img image.Image, includeTransparent bool) []extractPoint {
+	bounds := img.Bounds()
+
+	step := 1
+	for bounds.Dx()*bounds.Dy()/(step*step) > maxExtractPixels {
+		step++
+	}
+
+	counts := map[[3]uint8]int{}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			c := imagecolor.NRGBAModel.Convert(img.At(x, y)).(imagecolor.NRGBA)
+			if c.A < 0x80 && !includeTransparent {
+				continue
+			}
+			counts[[3]uint8{c.R, c.G, c.B}]++
+		}
+	}
+
+	points := make([]extractPoint, 0, len(counts))
+	for rgb, count := range counts {
+		l, a, b := rgbToLab(float64(rgb[0])/0xff, float64(rgb[1])/0xff, float64(rgb[2])/0xff)
+		points = append(points, extractPoint{lab: [3]float64{l, a, b}, weight: float64(count)})
+	}
+
+	//	map order is random, results shouldn't be
+	sort.Slice(points, func(i, j int) bool {
+		p, q := points[i].lab, points[j].lab
+		if p[0] != q[0] {
+			return p[0] < q[0]
+		}
+		if p[1] != q[1] {
+			return p[1] < q[1]
+		}
+		return p[2] < q[2]
+	})
+
+	return points
+}
+
+// Splits points into at most n boxes and returns their weighted means.
+func medianCut(points []extractPoint, n int) []extractPoint {
+	boxes := [][]extractPoint{points}
+
+	for len(boxes) < n {
+		//	cut the box spanning the longest distance along any axis
+		best, axis, longest := -1, 0, 0.0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			for a := 0; a < 3; a++ {
+				min, max := math.Inf(1), math.Inf(-1)
+				for _, p := range box {
+					min, max = math.Min(min, p.lab[a]), math.Max(max, p.lab[a])
+				}
+				if max-min > longest {
+					best, axis, longest = i, a, max-min
+				}
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		box := boxes[best]
+		sort.SliceStable(box, func(i, j int) bool {
+			return box[i].lab[axis] < box[j].lab[axis]
+		})
+
+		//	cut at the weighted median, leaving at least one point each side
+		var total, sum float64
+		for _, p := range box {
+			total += p.weight
+		}
+		cut := 1
+		for i, p := range box[:len(box)-1] {
+			sum += p.weight
+			if sum >= total/2 {
+				cut = i + 1
+				break
+			}
+		}
+
+		boxes[best] = box[:cut]
+		boxes = append(boxes, box[cut:])
+	}
+
+	means := make([]extractPoint, len(boxes))
+	for i, box := range boxes {
+		means[i] = weightedMean(box)
+	}
+	return means
+}
+
+// Moves centers to the weighted mean of the points closest to them until they
+// settle, and returns them with the weight of their points. Centers left with
+// no points are dropped.
+func kMeans(points []extractPoint, centers []extractPoint) []extractPoint {
+	assigned := make([]int, len(points))
+	clusters := make([][]extractPoint, len(centers))
+
+	for iteration := 0; iteration < 50; iteration++ {
+		changed := false
+		for i := range clusters {
+			clusters[i] = clusters[i][:0]
+		}
+
+		for i, p := range points {
+			closest, dist := 0, math.Inf(1)
+			for j, c := range centers {
+				d := sq(p.lab[0]-c.lab[0]) + sq(p.lab[1]-c.lab[1]) + sq(p.lab[2]-c.lab[2])
+				if d < dist {
+					closest, dist = j, d
+				}
+			}
+			if assigned[i] != closest || iteration == 0 {
+				assigned[i] = closest
+				changed = true
+			}
+			clusters[closest] = append(clusters[closest], p)
+		}
+
+		for i, cluster := range clusters {
+			if len(cluster) > 0 {
+				centers[i] = weightedMean(cluster)
+			} else {
+				centers[i].weight = 0
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	kept := centers[:0]
+	for _, c := range centers {
+		if c.weight > 0 {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// Returns the weighted mean of points, weighing as much as all of them.
+func weightedMean(points []extractPoint) (m extractPoint) {
+	for _, p := range points {
+		for a := range m.lab {
+			m.lab[a] += p.lab[a] * p.weight
+		}
+		m.weight += p.weight
+	}
+	for a := range m.lab {
+		m.lab[a] /= m.weight
+	}
+	return
+}
@@ -0,0 +1,85 @@
+package ase
+
+import (
+	"errors"
+	"image"
+	imagecolor "image/color"
+	"testing"
+)
+
+// Returns a 10x10 image, half red, 30% blue, 20% green, with a transparent
+// white row on top.
+func testImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 11))
+	for y := 0; y < 11; y++ {
+		for x := 0; x < 10; x++ {
+			c := imagecolor.NRGBA{0xff, 0, 0, 0xff}
+			switch {
+			case y == 0:
+				c = imagecolor.NRGBA{0xff, 0xff, 0xff, 0x10}
+			case x >= 8:
+				c = imagecolor.NRGBA{0, 0xff, 0, 0xff}
+			case x >= 5:
+				c = imagecolor.NRGBA{0, 0, 0xff, 0xff}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestExtract(t *testing.T) {
+	for _, method := range []ExtractMethod{MedianCut, KMeans} {
+		group, err := Extract(testImage(), ExtractOptions{Method: method, Name: "Image", HexNames: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if group.Name != "Image" || len(group.Colors) != 3 {
+			t.Fatal("expected 3 colors, got", group)
+		}
+		for i, expected := range []string{"#FF0000", "#0000FF", "#00FF00"} {
+			c := group.Colors[i]
+			if hex, _ := c.Hex(); c.Name != expected || hex != expected || c.Model != RGB || c.Type != Normal {
+				t.Errorf("method %d, color %d: expected %s, got %v", method, i, expected, c)
+			}
+		}
+	}
+
+	group, err := Extract(testImage(), ExtractOptions{Colors: 8, IncludeTransparent: true, Type: Spot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(group.Colors) != 4 || group.Colors[3].Name != "Color 4" || group.Colors[3].Type != Spot {
+		t.Error("expected the transparent white last, got", group.Colors)
+	}
+	if hex, _ := group.Colors[3].Hex(); hex != "#FFFFFF" {
+		t.Error("expected white, got", hex)
+	}
+
+	// Two colors out of three: red and blue, the closest in LAB, share one.
+	group, _ = Extract(testImage(), ExtractOptions{Colors: 2, HexNames: true})
+	if len(group.Colors) != 2 || group.Colors[0].Name != "#D3006C" || group.Colors[1].Name != "#00FF00" {
+		t.Error("expected a mix of red and blue then green, got", group.Colors)
+	}
+
+	// A gradient has more colors than asked for.
+	gradient := image.NewGray(image.Rect(0, 0, 256, 1))
+	for x := 0; x < 256; x++ {
+		gradient.SetGray(x, 0, imagecolor.Gray{uint8(x)})
+	}
+	for _, method := range []ExtractMethod{MedianCut, KMeans} {
+		group, _ = Extract(gradient, ExtractOptions{Colors: 5, Method: method})
+		if len(group.Colors) != 5 {
+			t.Error("expected 5 colors, got", group.Colors)
+		}
+		if err = group.Validate(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	empty := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	if _, err = Extract(empty, ExtractOptions{}); !errors.Is(err, ErrNoPixels) {
+		t.Error("expected ErrNoPixels, got", err)
+	}
+}
@@ -0,0 +1,186 @@
+package ase
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidGPL = errors.New("ase: file not a GIMP palette")
+
+// GPLInfo holds the parts of a GIMP palette (.gpl) that have no place in an ASE.
+type GPLInfo struct {
+	Name     string       // the Name: header
+	Columns  int          // the Columns: header, 0 when missing
+	Comments []GPLComment // comment lines in file order
+}
+
+// A GPLComment is a comment line of a GIMP palette.
+type GPLComment struct {
+	Text   string // the comment, without the leading #
+	Before int    // number of colors that come before it
+}
+
+// Decodes a GIMP or Inkscape palette (.gpl). Its colors become RGB colors of
+// type Normal. If the palette has a name, they go into a group named after it,
+// otherwise they are loose.
+func DecodeGPL(r io.Reader) (ase ASE, info GPLInfo, err error) {
+	s := bufio.NewScanner(r)
+
+	if !s.Scan() || strings.TrimSpace(strings.TrimPrefix(s.Text(), "\ufeff")) != "GIMP Palette" {
+		if err = s.Err(); err == nil {
+			err = ErrInvalidGPL
+		}
+		return
+	}
+
+	var colors []Color
+	header := true
+	for line := 2; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(text, "#"):
+			info.Comments = append(info.Comments, GPLComment{Text: strings.TrimPrefix(text, "#"), Before: len(colors)})
+			continue
+		}
+
+		//	headers come before the first color
+		if header {
+			if name, ok := cutPrefix(text, "Name:"); ok {
+				info.Name = strings.TrimSpace(name)
+				continue
+			}
+			if columns, ok := cutPrefix(text, "Columns:"); ok {
+				if info.Columns, err = strconv.Atoi(strings.TrimSpace(columns)); err != nil {
+					err = fmt.Errorf("%w: line %d: invalid columns %q", ErrInvalidGPL, line, columns)
+					return
+				}
+				continue
+			}
+		}
+		header = false
+
+		var c Color
+		if c, err = parseGPLColor(text); err != nil {
+			err = fmt.Errorf("%w: line %d: %v", ErrInvalidGPL, line, err)
+			return
+		}
+		colors = append(colors, c)
+	}
+	if err = s.Err(); err != nil {
+		return
+	}
+
+	if info.Name != "" {
+		ase.Groups = []Group{{Name: info.Name, Colors: colors}}
+	} else {
+		ase.Colors = colors
+	}
+
+	return
+}
+
+// Encodes the colors of an ASE, loose and grouped, as a GIMP palette. Colors
+// are converted to 8 bit RGB. Groups are flattened. info gives the palette's
+// headers and comments, see DecodeGPL. Without a name in info, an ASE made of
+// a single group is named after it.
+func EncodeGPL(ase ASE, w io.Writer, info GPLInfo) (err error) {
+	name := info.Name
+	if name == "" && len(ase.Colors) == 0 && len(ase.Groups) == 1 {
+		name = ase.Groups[0].Name
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "GIMP Palette")
+	if name != "" {
+		fmt.Fprintln(bw, "Name:", oneLine(name))
+	}
+	if info.Columns > 0 {
+		fmt.Fprintln(bw, "Columns:", info.Columns)
+	}
+
+	//	comments go back where they were, relative to the colors
+	comments := info.Comments
+	writeComments := func(before int) {
+		for len(comments) > 0 && comments[0].Before <= before {
+			fmt.Fprintln(bw, "#"+oneLine(comments[0].Text))
+			comments = comments[1:]
+		}
+	}
+
+	i := 0
+	ase.eachColor(func(group *Group, color *Color) {
+		if err != nil {
+			return
+		}
+		if err = color.checkValues(); err != nil {
+			err = fmt.Errorf("ase: color %q: %w", color.Name, err)
+			return
+		}
+
+		writeComments(i)
+		r, g, b := color.rgb(NaiveCMYK{})
+		fmt.Fprintf(bw, "%3d %3d %3d", to8(r), to8(g), to8(b))
+		if color.Name != "" {
+			fmt.Fprint(bw, "\t"+oneLine(color.Name))
+		}
+		fmt.Fprintln(bw)
+		i++
+	})
+	if err != nil {
+		return
+	}
+
+	//	whatever is left goes at the end
+	for _, comment := range comments {
+		fmt.Fprintln(bw, "#"+oneLine(comment.Text))
+	}
+
+	return bw.Flush()
+}
+
+// Parses a "R G B name" row.
+func parseGPLColor(text string) (c Color, err error) {
+	fields := strings.Fields(text)
+	if len(fields) < 3 {
+		return c, fmt.Errorf("expected R G B, got %q", text)
+	}
+
+	c = Color{Model: RGB, Type: Normal}
+	for _, field := range fields[:3] {
+		v, err := strconv.ParseUint(field, 10, 8)
+		if err != nil {
+			return c, fmt.Errorf("invalid component %q", field)
+		}
+		c.Values = append(c.Values, float32(v)/255)
+	}
+
+	//	the name is everything after the components, spaces included
+	rest := text
+	for _, field := range fields[:3] {
+		rest = strings.TrimLeft(rest, " \t")
+		rest = rest[len(field):]
+	}
+	c.Name = strings.TrimSpace(rest)
+
+	return
+}
+
+// Returns s with line breaks replaced by spaces.
+func oneLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// Reports whether s starts with prefix, and returns s without it.
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
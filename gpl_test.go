@@ -0,0 +1,85 @@
+package ase
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testGPL = `GIMP Palette
+Name: Brand Colors
+Columns: 3
+#
+# Primary colors
+255   0   0	Red
+  0 255   0	Bright Green
+# Secondary colors
+  0   0 255
+# end
+`
+
+func TestDecodeGPL(t *testing.T) {
+	ase, info, err := DecodeGPL(strings.NewReader(testGPL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Name != "Brand Colors" || info.Columns != 3 || len(info.Comments) != 4 {
+		t.Errorf("unexpected info %+v", info)
+	}
+	if info.Comments[1].Text != " Primary colors" || info.Comments[2].Before != 2 {
+		t.Errorf("unexpected comments %+v", info.Comments)
+	}
+
+	if len(ase.Groups) != 1 || ase.Groups[0].Name != "Brand Colors" {
+		t.Fatalf("expected a Brand Colors group, got %+v", ase)
+	}
+
+	colors := ase.Groups[0].Colors
+	if len(colors) != 3 || colors[1].Name != "Bright Green" || colors[2].Name != "" {
+		t.Fatalf("unexpected colors %+v", colors)
+	}
+	if !closeTo(colors[0].Values, []float32{1, 0, 0}, 0) || colors[0].Model != RGB || colors[0].Type != Normal {
+		t.Errorf("unexpected color %+v", colors[0])
+	}
+
+	// Encoding gives back the same palette.
+	b := new(bytes.Buffer)
+	if err = EncodeGPL(ase, b, info); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != testGPL {
+		t.Errorf("expected\n%s\ngot\n%s", testGPL, b.String())
+	}
+}
+
+func TestEncodeGPL(t *testing.T) {
+	sampleAse := ASE{Colors: testColors, Groups: []Group{testGroup}}
+
+	b := new(bytes.Buffer)
+	if err := EncodeGPL(sampleAse, b, GPLInfo{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Non RGB colors are converted.
+	lines := strings.Split(b.String(), "\n")
+	if lines[0] != "GIMP Palette" || lines[3] != "255   0 255\tcmyk" || lines[7] != "  0 255   0\tGreen" {
+		t.Errorf("unexpected palette\n%s", b.String())
+	}
+
+	ase, _, err := DecodeGPL(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ase.Colors) != 8 {
+		t.Error("expected 8 loose colors, got", len(ase.Colors))
+	}
+
+	if _, _, err = DecodeGPL(strings.NewReader("GIMP Palette\n255 0\n")); !errors.Is(err, ErrInvalidGPL) {
+		t.Error("expected", ErrInvalidGPL, "got", err)
+	}
+	if _, _, err = DecodeGPL(strings.NewReader("JASC-PAL\n")); err != ErrInvalidGPL {
+		t.Error("expected", ErrInvalidGPL, "got", err)
+	}
+}
@@ -11,6 +11,19 @@ type Group struct {
 	nameLen uint16
 	Name    string
 	Colors  []Color
+
+	// Entries holds the group's colors and raw blocks in file order, the
+	// same way ASE.Entries does for the top level: it is what Encode writes
+	// when it is set, and colors are added, removed or moved through it.
+	// When Entries is empty, the group's Colors are written instead.
+	Entries []Entry
+
+	// RawStart and RawEnd are set when decoding leniently and the group's
+	// start or end block is malformed. Encode writes them back as they are
+	// in place of the blocks it would build; set them to nil to have those
+	// built from Name instead.
+	RawStart *RawBlock
+	RawEnd   *RawBlock
 }
 
 // Decode an ASE group.
@@ -49,29 +62,26 @@ func (group *Group) readName(r io.Reader) (err error) {
 func (group *Group) write(w io.Writer) (err error) {
 
 	// Write group start headers (block entry, block length, nameLen, name)
-	if err = group.writeBlockStart(w); err != nil {
-		return
-	}
-
-	if err = group.writeBlockLength(w); err != nil {
-		return
+	if group.RawStart != nil {
+		err = group.RawStart.write(w)
+	} else {
+		err = group.writeStart(w)
 	}
-
-	if err = group.writeNameLen(w); err != nil {
-		return
-	}
-	if err = group.writeName(w); err != nil {
+	if err != nil {
 		return
 	}
 
 	// Encode the group's color data.
-	for _, color := range group.Colors {
-		if err = color.write(w); err != nil {
+	for _, entry := range group.entries() {
+		if err = entry.write(w); err != nil {
 			return
 		}
 	}
 
 	// Write the group's closing headers
+	if group.RawEnd != nil {
+		return group.RawEnd.write(w)
+	}
 	if err = group.writeBlockEnd(w); err != nil {
 		return
 	}
@@ -79,6 +89,22 @@ func (group *Group) write(w io.Writer) (err error) {
 	return
 }
 
+// Encode the group's start block: block entry, block length, nameLen and name.
+func (group *Group) writeStart(w io.Writer) (err error) {
+	if err = group.writeBlockStart(w); err != nil {
+		return
+	}
+
+	if err = group.writeBlockLength(w); err != nil {
+		return
+	}
+
+	if err = group.writeNameLen(w); err != nil {
+		return
+	}
+	return group.writeName(w)
+}
+
 // Wrapper around writing a group start header.
 func (group *Group) writeBlockStart(w io.Writer) (err error) {
 	return binary.Write(w, binary.BigEndian, groupStart)
@@ -98,8 +124,13 @@ func (group *Group) writeBlockEnd(w io.Writer) (err error) {
 
 // Encode the color's name length.
 func (group *Group) writeNameLen(w io.Writer) (err error) {
+	n := nameLen(group.Name)
+	if n > maxNameLen {
+		return ErrNameTooLong
+	}
+
 	// Adding one to the name length accounts for the zero-terminated character.
-	return binary.Write(w, binary.BigEndian, group.NameLen()+1)
+	return binary.Write(w, binary.BigEndian, uint16(n+1))
 }
 
 // Encode the group's name.
@@ -109,9 +140,10 @@ func (group *Group) writeName(w io.Writer) (err error) {
 	return binary.Write(w, binary.BigEndian, name)
 }
 
-// Helper function that returns the length of a group's name.
+// Helper function that returns the length of a group's name in UTF-16 code
+// units, the way it is stored in a file.
 func (group *Group) NameLen() uint16 {
-	return uint16(len(group.Name))
+	return uint16(nameLen(group.Name))
 }
 
 // Write color's block length as a part of the ASE encoding.
@@ -137,3 +169,56 @@ func (group *Group) calculateBlockLength() (val int32, err error) {
 
 	return
 }
+
+// A group is encoded as a start block, an end block and the blocks of its
+// colors or entries.
+func (group *Group) blockCount() (numBlocks int32) {
+	for _, entry := range group.entries() {
+		numBlocks += entry.blockCount()
+	}
+
+	return numBlocks + 2
+}
+
+// Sets Colors and Entries from entries, the same way ASE.fill does.
+func (group *Group) fill(entries []Entry) {
+	group.Colors, group.Entries = nil, nil
+
+	for _, entry := range entries {
+		if color, ok := entry.(*Color); ok {
+			group.Colors = append(group.Colors, *color)
+		}
+	}
+
+	var colors int
+	for _, entry := range entries {
+		if _, ok := entry.(*Color); ok {
+			entry = &group.Colors[colors]
+			colors++
+		}
+		group.Entries = append(group.Entries, entry)
+	}
+}
+
+// Returns the entries of the group Encode would write, in order: Entries, or
+// Colors when it's empty.
+func (group *Group) entries() []Entry {
+	if len(group.Entries) > 0 {
+		return group.Entries
+	}
+
+	entries := make([]Entry, len(group.Colors))
+	for i := range group.Colors {
+		entries[i] = &group.Colors[i]
+	}
+	return entries
+}
+
+// Calls fn for every color of the group Encode would write, in order.
+func (group *Group) eachColor(fn func(group *Group, color *Color)) {
+	for _, entry := range group.entries() {
+		if color, ok := entry.(*Color); ok {
+			fn(group, color)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package ase
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// HTMLOptions controls the page written by RenderHTML.
+type HTMLOptions struct {
+	// Title heads the page, "Swatches" when empty.
+	Title string
+}
+
+// Writes a self-contained HTML page listing every group and color, in order.
+//
+// Each color shows its original model and values, and its hex, RGB, CMYK and
+// LAB codes, which copy to the clipboard when clicked. Its WCAG 2 contrast
+// ratio against black and white text tells which one reads better on it, and
+// a badge gives its type. The page has no external resources.
+func RenderHTML(ase ASE, w io.Writer, opts HTMLOptions) error {
+	page := htmlPage{Title: opts.Title}
+	if page.Title == "" {
+		page.Title = "Swatches"
+	}
+
+	for _, sec := range ase.sections() {
+		hs := htmlSection{}
+		if sec.group != nil {
+			hs.Title, hs.Group = sec.group.Name, true
+		}
+		for _, color := range sec.colors {
+			hs.Colors = append(hs.Colors, htmlSwatch(color))
+		}
+		page.Sections = append(page.Sections, hs)
+	}
+
+	return htmlTemplate.Execute(w, page)
+}
+
+// The data of the page template.
+type htmlPage struct {
+	Title    string
+	Sections []htmlSection
+}
+
+type htmlSection struct {
+	Title  string
+	Group  bool
+	Colors []htmlColor
+}
+
+type htmlColor struct {
+	Name   string
+	Type   string
+	Model  string
+	Values string
+	Err    error
+
+	Fill   template.CSS // the hex code, safe in a style attribute
+	Codes  [][2]string  // label and value
+	Black  htmlContrast // black text on the color
+	White  htmlContrast // white text on the color
+	Prefer string       // "black" or "white", whichever reads better
+}
+
+type htmlContrast struct {
+	Ratio string
+	Grade string
+}
+
+// Gathers what the page shows about a color.
+func htmlSwatch(color *Color) (hc htmlColor) {
+	hc.Name, hc.Type, hc.Model = color.Name, color.Type.String(), color.Model.String()
+
+	values := make([]string, len(color.Values))
+	for i, v := range color.Values {
+		values[i] = formatNumber(float64(v), 4)
+	}
+	hc.Values = strings.Join(values, " ")
+
+	if hc.Err = color.checkValues(); hc.Err != nil {
+		return
+	}
+
+	hex, _ := color.Hex()
+	rgb, _ := color.CSS(CSSRGB, 0)
+	lab, _ := color.CSS(CSSLab, 2)
+	cmyk, _ := color.ToCMYK()
+	percents := make([]string, len(cmyk.Values))
+	for i, v := range cmyk.Values {
+		percents[i] = formatNumber(float64(v)*100, 0) + "%"
+	}
+
+	hc.Fill = template.CSS(hex)
+	hc.Codes = [][2]string{
+		{"HEX", hex},
+		{"RGB", rgb},
+		{"CMYK", "cmyk(" + strings.Join(percents, " ") + ")"},
+		{"LAB", lab},
+	}
+
+	//	relative luminance of the color as shown, 0 for black and 1 for white
+	l := luminance(color.rgb(NaiveCMYK{}))
+	black, white := contrastRatio(l, 0), contrastRatio(l, 1)
+	hc.Black = htmlContrast{fmt.Sprintf("%.2f", black), wcagGrade(black)}
+	hc.White = htmlContrast{fmt.Sprintf("%.2f", white), wcagGrade(white)}
+	hc.Prefer = "black"
+	if white > black {
+		hc.Prefer = "white"
+	}
+
+	return
+}
+
+var htmlTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font: 14px/1.4 system-ui, sans-serif; color: #333; margin: 2rem; }
+h2 { margin: 2rem 0 1rem; }
+.swatches { display: grid; grid-template-columns: repeat(auto-fill, minmax(220px, 1fr)); gap: 1rem; }
+.swatch { border: 1px solid #ddd; border-radius: 6px; overflow: hidden; }
+.chip { height: 96px; display: flex; align-items: flex-end; justify-content: space-between; padding: 0 .5rem .25rem; font-size: 12px; border-bottom: 1px solid #ddd; }
+.chip.invalid { background: repeating-linear-gradient(45deg, #fff, #fff 8px, #eee 8px, #eee 16px); }
+.chip .on-black { color: #000; }
+.chip .on-white { color: #fff; }
+.chip .preferred { font-weight: bold; }
+.info { padding: .5rem; }
+.name { font-weight: bold; margin: 0 0 .25rem; overflow-wrap: anywhere; }
+.badge { font-size: 11px; font-weight: normal; padding: 0 .4em; border-radius: 1em; margin-left: .25em; color: #fff; }
+.badge.Global { background: #2a6; }
+.badge.Spot { background: #c33; }
+.badge.Normal { background: #888; }
+.source { color: #777; margin: 0 0 .25rem; font-size: 12px; }
+dl { display: grid; grid-template-columns: auto 1fr; gap: 0 .5rem; margin: 0; font-size: 12px; }
+dt { color: #777; }
+dd { margin: 0; }
+button.copy { font: 12px ui-monospace, monospace; border: 0; padding: 0; background: none; cursor: copy; text-align: left; }
+button.copy:hover { text-decoration: underline; }
+button.copy.copied::after { content: " copied"; color: #2a6; }
+.error { color: #c33; font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Sections}}<section>
+{{if .Group}}<h2>{{.Title}}</h2>
+{{end}}<div class="swatches">
+{{range .Colors}}<article class="swatch">
+{{if .Err}}<div class="chip invalid"></div>
+{{else}}<div class="chip" style="background: {{.Fill}}">
+<span class="on-black{{if eq .Prefer "black"}} preferred{{end}}" title="black text: {{.Black.Grade}}">Aa {{.Black.Ratio}}</span>
+<span class="on-white{{if eq .Prefer "white"}} preferred{{end}}" title="white text: {{.White.Grade}}">Aa {{.White.Ratio}}</span>
+</div>
+{{end}}<div class="info">
+<p class="name">{{.Name}} <span class="badge {{.Type}}">{{.Type}}</span></p>
+<p class="source">{{.Model}} {{.Values}}</p>
+{{if .Err}}<p class="error">{{.Err}}</p>
+{{else}}<dl>
+{{range .Codes}}<dt>{{index . 0}}</dt><dd><button class="copy" type="button" data-copy="{{index . 1}}">{{index . 1}}</button></dd>
+{{end}}<dt>black text</dt><dd>{{.Black.Ratio}}:1 {{.Black.Grade}}</dd>
+<dt>white text</dt><dd>{{.White.Ratio}}:1 {{.White.Grade}}</dd>
+</dl>
+{{end}}</div>
+</article>
+{{end}}</div>
+</section>
+{{end}}<script>
+document.addEventListener("click", function (e) {
+	var b = e.target.closest("button.copy");
+	if (!b || !navigator.clipboard) return;
+	navigator.clipboard.writeText(b.dataset.copy).then(function () {
+		b.classList.add("copied");
+		setTimeout(function () { b.classList.remove("copied"); }, 1000);
+	});
+});
+</script>
+</body>
+</html>
+`))
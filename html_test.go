@@ -0,0 +1,53 @@
+package ase
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	var b bytes.Buffer
+	if err := RenderHTML(testSheet(), &b, HTMLOptions{Title: "Brand & co"}); err != nil {
+		t.Fatal(err)
+	}
+	page := b.String()
+
+	for _, s := range []string{
+		"<title>Brand &amp; co</title>",
+		"<h2>Brand &lt;&amp;&gt;</h2>",
+		`<div class="chip" style="background: #FF0000">`,
+		`<span class="badge Global">Global</span>`,
+		`<span class="badge Spot">Spot</span>`,
+		`data-copy="rgb(255 0 0)"`,
+		`data-copy="cmyk(0% 100% 100% 0%)"`,
+		`data-copy="lab(54.29 80.81 69.89)"`,
+		// white on red is 4.00:1, black on red 5.25:1
+		"<dd>5.25:1 AA</dd>",
+		"<dd>4.00:1 AA large</dd>",
+		// black and white against black
+		`<span class="on-white preferred" title="white text: AAA">Aa 21.00</span>`,
+		`<span class="on-black" title="black text: fail">Aa 1.00</span>`,
+		`<div class="chip invalid"></div>`,
+		"wrong number of values",
+		`<p class="source">LAB 0.5 20 -30</p>`,
+	} {
+		if !strings.Contains(page, s) {
+			t.Errorf("expected %q in the page", s)
+		}
+	}
+
+	// Loose colors don't get a heading.
+	if strings.Count(page, "<h2>") != 1 {
+		t.Error("expected one heading")
+	}
+	if strings.Contains(page, "http") {
+		t.Error("expected no external resources")
+	}
+
+	b.Reset()
+	RenderHTML(ASE{}, &b, HTMLOptions{})
+	if !strings.Contains(b.String(), "<title>Swatches</title>") {
+		t.Error("expected the default title")
+	}
+}
@@ -0,0 +1,81 @@
+package ase
+
+import (
+	"fmt"
+	imagecolor "image/color"
+)
+
+// Implements image/color.Color. The color is converted to sRGB first, see
+// ToRGB, and is fully opaque. Colors that can't be converted are black.
+func (color Color) RGBA() (r, g, b, a uint32) {
+	if color.checkValues() != nil {
+		return 0, 0, 0, 0xffff
+	}
+
+	rf, gf, bf := color.rgb(NaiveCMYK{})
+
+	return to16(rf), to16(gf), to16(bf), 0xffff
+}
+
+// Returns an RGB color named name with the same sRGB components as c.
+// Transparency is dropped, as ASE colors are always opaque.
+func FromColor(c imagecolor.Color, name string, colorType ColorType) Color {
+	nrgba := imagecolor.NRGBA64Model.Convert(c).(imagecolor.NRGBA64)
+
+	return Color{
+		Name:   name,
+		Model:  RGB,
+		Values: []float32{float32(nrgba.R) / 0xffff, float32(nrgba.G) / 0xffff, float32(nrgba.B) / 0xffff},
+		Type:   colorType,
+	}
+}
+
+// Returns every color Encode would write, loose and grouped, in order.
+func (ase *ASE) Palette() (p imagecolor.Palette) {
+	ase.eachColor(func(group *Group, color *Color) {
+		p = append(p, *color)
+	})
+	return
+}
+
+// Returns the group's colors in order.
+func (group *Group) Palette() (p imagecolor.Palette) {
+	group.eachColor(func(group *Group, color *Color) {
+		p = append(p, *color)
+	})
+	return
+}
+
+// Returns an ASE holding the colors of p as loose RGB colors of the given type.
+// Colors that are already ASE colors are kept as they are, the others are
+// named after their hex code, such as "#FF8000".
+func FromPalette(p imagecolor.Palette, colorType ColorType) ASE {
+	return ASE{Colors: paletteColors(p, colorType)}
+}
+
+// Returns a group named name holding the colors of p, see FromPalette.
+func GroupFromPalette(name string, p imagecolor.Palette, colorType ColorType) Group {
+	return Group{Name: name, Colors: paletteColors(p, colorType)}
+}
+
+// Converts the colors of a palette to ASE colors.
+func paletteColors(p imagecolor.Palette, colorType ColorType) []Color {
+	colors := make([]Color, len(p))
+	for i, c := range p {
+		switch c := c.(type) {
+		case Color:
+			colors[i] = c
+		case *Color:
+			colors[i] = *c
+		default:
+			nrgba := imagecolor.NRGBAModel.Convert(c).(imagecolor.NRGBA)
+			colors[i] = FromColor(c, fmt.Sprintf("#%02X%02X%02X", nrgba.R, nrgba.G, nrgba.B), colorType)
+		}
+	}
+	return colors
+}
+
+// Scales v from [0, 1] to a 16 bit color component.
+func to16(v float64) uint32 {
+	return uint32(clip(v)*0xffff + 0.5)
+}
@@ -0,0 +1,69 @@
+package ase
+
+import (
+	"image"
+	imagecolor "image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestRGBA(t *testing.T) {
+	tests := []struct {
+		color    Color
+		expected imagecolor.RGBA64
+	}{
+		{Color{Model: RGB, Values: []float32{1, 0, 0.5}}, imagecolor.RGBA64{0xffff, 0, 0x8000, 0xffff}},
+		{Color{Model: CMYK, Values: []float32{0, 1, 0, 0}}, imagecolor.RGBA64{0xffff, 0, 0xffff, 0xffff}},
+		{Color{Model: Gray, Values: []float32{0}}, imagecolor.RGBA64{0, 0, 0, 0xffff}},
+		{Color{Model: LAB, Values: []float32{1, 0, 0}}, imagecolor.RGBA64{0xffff, 0xffff, 0xffff, 0xffff}},
+		{Color{Model: RGB, Values: []float32{1}}, imagecolor.RGBA64{0, 0, 0, 0xffff}},
+	}
+
+	for _, test := range tests {
+		r, g, b, a := test.color.RGBA()
+		actual := imagecolor.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+		if actual != test.expected {
+			t.Errorf("%s %v: expected %v, got %v", test.color.Model, test.color.Values, test.expected, actual)
+		}
+	}
+}
+
+func TestFromColor(t *testing.T) {
+	c := FromColor(imagecolor.NRGBA{0xff, 0x80, 0x00, 0x80}, "Orange", Global)
+
+	if c.Name != "Orange" || c.Model != RGB || c.Type != Global {
+		t.Errorf("unexpected color %+v", c)
+	}
+	if !closeTo(c.Values, []float32{1, 0x80 / 255.0, 0}, 1e-4) {
+		t.Error("unexpected values", c.Values)
+	}
+}
+
+func TestPalette(t *testing.T) {
+	sampleAse := ASE{Colors: testColors, Groups: []Group{testGroup}}
+
+	p := sampleAse.Palette()
+	if len(p) != len(testColors)+len(testGroup.Colors) {
+		t.Fatal("expected every color in the palette, got", len(p))
+	}
+
+	// The palette is usable by image/draw as is.
+	src := image.NewUniform(imagecolor.RGBA{0xf0, 0x10, 0x10, 0xff})
+	dst := image.NewPaletted(image.Rect(0, 0, 1, 1), p)
+	draw.Draw(dst, dst.Bounds(), src, image.Point{}, draw.Src)
+	if matched := p[dst.ColorIndexAt(0, 0)].(Color); matched.Name != "Red" {
+		t.Error("expected Red, got", matched.Name)
+	}
+
+	// And back again.
+	group := GroupFromPalette("Mixed", imagecolor.Palette{testGroup.Colors[0], imagecolor.White}, Spot)
+	if group.Colors[0].Name != "Red" || group.Colors[0].Type != Global {
+		t.Errorf("expected ASE colors to be kept, got %+v", group.Colors[0])
+	}
+	if group.Colors[1].Name != "#FFFFFF" || group.Colors[1].Type != Spot {
+		t.Errorf("expected a spot color named #FFFFFF, got %+v", group.Colors[1])
+	}
+	if len(FromPalette(group.Palette(), Spot).Colors) != 2 {
+		t.Error("expected 2 loose colors")
+	}
+}
@@ -0,0 +1,326 @@
+package ase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ASE, Group and Color marshal to JSON following this schema:
+//
+//	{
+//	  "version": "1.0",
+//	  "entries": [
+//	    {"name": "RGB", "model": "RGB", "values": [1, 1, 1], "type": "Normal"},
+//	    {"name": "A Color Group", "colors": [
+//	      {"name": "Red", "model": "RGB", "values": [1, 0, 0], "type": "Global"}
+//	    ]},
+//	    {"block": 48879, "data": "eHl6"}
+//	  ]
+//	}
+//
+// Entries are listed in the order Encode writes them. A group is told apart
+// by its "colors" and a raw block, kept from lenient decoding, by its "block"
+// type, its payload being base64 encoded. Everything else is a color. Groups
+// list their raw blocks among their colors, and a malformed start or end block
+// kept by lenient decoding as a raw block under "start" or "end". The errors
+// of raw blocks aren't kept. Models and types are written as their names and
+// read ignoring case.
+//
+// The YAML form has the same shape. ASE, Group and Color implement the
+// Marshaler and Unmarshaler interfaces of gopkg.in/yaml.v2, which yaml.v3
+// understands as well.
+
+// The JSON and YAML form of a color.
+type colorDoc struct {
+	Name   string     `json:"name" yaml:"name"`
+	Model  ColorModel `json:"model" yaml:"model"`
+	Values []float32  `json:"values" yaml:"values,flow"`
+	Type   ColorType  `json:"type" yaml:"type"`
+}
+
+// The JSON and YAML form of a group.
+type groupDoc struct {
+	Name   string        `json:"name" yaml:"name"`
+	Colors []interface{} `json:"colors" yaml:"colors"`
+	Start  *rawDoc       `json:"start,omitempty" yaml:"start,omitempty"`
+	End    *rawDoc       `json:"end,omitempty" yaml:"end,omitempty"`
+}
+
+// The JSON and YAML form of a raw block.
+type rawDoc struct {
+	Block uint16 `json:"block" yaml:"block"`
+	Data  string `json:"data" yaml:"data"`
+}
+
+// The JSON and YAML form of an ASE.
+type aseDoc struct {
+	Version FileVersion   `json:"version" yaml:"version"`
+	Entries []interface{} `json:"entries" yaml:"entries"`
+}
+
+// Any entry when unmarshalling, told apart by the fields it has.
+type entryDoc struct {
+	Name   string      `json:"name" yaml:"name"`
+	Model  ColorModel  `json:"model" yaml:"model"`
+	Values []float32   `json:"values" yaml:"values"`
+	Type   ColorType   `json:"type" yaml:"type"`
+	Colors *[]entryDoc `json:"colors" yaml:"colors"`
+	Block  *uint16     `json:"block" yaml:"block"`
+	Data   string      `json:"data" yaml:"data"`
+	Start  *entryDoc   `json:"start" yaml:"start"`
+	End    *entryDoc   `json:"end" yaml:"end"`
+}
+
+// An ASE when unmarshalling.
+type aseInDoc struct {
+	Version FileVersion `json:"version" yaml:"version"`
+	Entries []entryDoc  `json:"entries" yaml:"entries"`
+}
+
+// Implements json.Marshaler, see the schema above.
+func (ase ASE) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ase.doc())
+}
+
+// Implements json.Unmarshaler, see the schema above. Colors, Groups and
+// Entries are set the same way Decode sets them.
+func (ase *ASE) UnmarshalJSON(data []byte) (err error) {
+	var doc aseInDoc
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return
+	}
+	return ase.fromDoc(doc)
+}
+
+// Implements the yaml.v2 Marshaler interface.
+func (ase ASE) MarshalYAML() (interface{}, error) {
+	return ase.doc(), nil
+}
+
+// Implements the yaml.v2 Unmarshaler interface.
+func (ase *ASE) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	var doc aseInDoc
+	if err = unmarshal(&doc); err != nil {
+		return
+	}
+	return ase.fromDoc(doc)
+}
+
+// Implements json.Marshaler, see the schema above.
+func (group Group) MarshalJSON() ([]byte, error) {
+	return json.Marshal(group.doc())
+}
+
+// Implements json.Unmarshaler, see the schema above.
+func (group *Group) UnmarshalJSON(data []byte) (err error) {
+	var doc entryDoc
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return
+	}
+	return group.fromDoc(doc)
+}
+
+// Implements the yaml.v2 Marshaler interface.
+func (group Group) MarshalYAML() (interface{}, error) {
+	return group.doc(), nil
+}
+
+// Implements the yaml.v2 Unmarshaler interface.
+func (group *Group) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	var doc entryDoc
+	if err = unmarshal(&doc); err != nil {
+		return
+	}
+	return group.fromDoc(doc)
+}
+
+// Implements json.Marshaler, see the schema above.
+func (color Color) MarshalJSON() ([]byte, error) {
+	return json.Marshal(color.doc())
+}
+
+// Implements json.Unmarshaler, see the schema above.
+func (color *Color) UnmarshalJSON(data []byte) (err error) {
+	var doc entryDoc
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return
+	}
+	*color = doc.color()
+	return
+}
+
+// Implements the yaml.v2 Marshaler interface.
+func (color Color) MarshalYAML() (interface{}, error) {
+	return color.doc(), nil
+}
+
+// Implements the yaml.v2 Unmarshaler interface.
+func (color *Color) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	var doc entryDoc
+	if err = unmarshal(&doc); err != nil {
+		return
+	}
+	*color = doc.color()
+	return
+}
+
+// Implements encoding.TextMarshaler, as "major.minor". The zero version is
+// written as 1.0, like Encode does.
+func (v FileVersion) MarshalText() ([]byte, error) {
+	if v == (FileVersion{}) {
+		v = Version1
+	}
+	return []byte(v.String()), nil
+}
+
+// Implements encoding.TextUnmarshaler.
+func (v *FileVersion) UnmarshalText(text []byte) error {
+	major, minor, ok := strings.Cut(string(text), ".")
+	maj, err1 := strconv.ParseInt(major, 10, 16)
+	min, err2 := strconv.ParseInt(minor, 10, 16)
+	if !ok || err1 != nil || err2 != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidVersion, text)
+	}
+
+	v.Major, v.Minor = int16(maj), int16(min)
+
+	return nil
+}
+
+// Returns the ASE's JSON and YAML form.
+func (ase *ASE) doc() aseDoc {
+	doc := aseDoc{Version: ase.FileVersion, Entries: []interface{}{}}
+	for _, entry := range ase.entries() {
+		doc.Entries = append(doc.Entries, entryToDoc(entry))
+	}
+	return doc
+}
+
+// Returns the group's JSON and YAML form.
+func (group *Group) doc() groupDoc {
+	doc := groupDoc{Name: group.Name, Colors: []interface{}{}}
+	for _, entry := range group.entries() {
+		doc.Colors = append(doc.Colors, entryToDoc(entry))
+	}
+	if group.RawStart != nil {
+		start := group.RawStart.doc()
+		doc.Start = &start
+	}
+	if group.RawEnd != nil {
+		end := group.RawEnd.doc()
+		doc.End = &end
+	}
+	return doc
+}
+
+// Returns the raw block's JSON and YAML form.
+func (raw *RawBlock) doc() rawDoc {
+	return rawDoc{Block: raw.Type, Data: base64.StdEncoding.EncodeToString(raw.Data)}
+}
+
+// Returns the color's JSON and YAML form.
+func (color *Color) doc() colorDoc {
+	return colorDoc{Name: color.Name, Model: color.Model, Values: color.Values, Type: color.Type}
+}
+
+// Returns the JSON and YAML form of any entry.
+func entryToDoc(entry Entry) interface{} {
+	switch entry := entry.(type) {
+	case *Color:
+		return entry.doc()
+	case *Group:
+		return entry.doc()
+	case *RawBlock:
+		return entry.doc()
+	}
+	return nil
+}
+
+// Sets the ASE from its unmarshalled form.
+func (ase *ASE) fromDoc(doc aseInDoc) error {
+	entries, err := docsToEntries(doc.Entries, true)
+	if err != nil {
+		return err
+	}
+
+	*ase = ASE{FileVersion: doc.Version}
+	ase.fill(entries)
+
+	return nil
+}
+
+// Sets the group from its unmarshalled form.
+func (group *Group) fromDoc(doc entryDoc) error {
+	var colors []entryDoc
+	if doc.Colors != nil {
+		colors = *doc.Colors
+	}
+
+	entries, err := docsToEntries(colors, false)
+	if err != nil {
+		return err
+	}
+
+	*group = Group{Name: doc.Name}
+	group.fill(entries)
+
+	if doc.Start != nil {
+		if group.RawStart, err = doc.Start.raw(); err != nil {
+			return fmt.Errorf("ase: group start: %w", err)
+		}
+	}
+	if doc.End != nil {
+		if group.RawEnd, err = doc.End.raw(); err != nil {
+			return fmt.Errorf("ase: group end: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Returns the raw block an unmarshalled entry describes.
+func (doc entryDoc) raw() (raw *RawBlock, err error) {
+	raw = &RawBlock{}
+	if doc.Block != nil {
+		raw.Type = *doc.Block
+	}
+	if raw.Data, err = base64.StdEncoding.DecodeString(doc.Data); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Returns the color an unmarshalled entry describes.
+func (doc entryDoc) color() Color {
+	return Color{Name: doc.Name, Model: doc.Model, Values: doc.Values, Type: doc.Type}
+}
+
+// Converts unmarshalled entries. Groups are only allowed at the top level.
+func docsToEntries(docs []entryDoc, groups bool) (entries []Entry, err error) {
+	for i, doc := range docs {
+		switch {
+		case doc.Block != nil:
+			var raw *RawBlock
+			if raw, err = doc.raw(); err != nil {
+				return nil, fmt.Errorf("ase: entry %d: %w", i, err)
+			}
+			entries = append(entries, raw)
+		case doc.Colors != nil:
+			if !groups {
+				return nil, fmt.Errorf("ase: entry %d: groups can't be nested", i)
+			}
+			g := &Group{}
+			if err = g.fromDoc(doc); err != nil {
+				return
+			}
+			entries = append(entries, g)
+		default:
+			c := doc.color()
+			entries = append(entries, &c)
+		}
+	}
+	return
+}
@@ -0,0 +1,152 @@
+package ase
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestJSON(t *testing.T) {
+	ase, err := DecodeFile("samples/test.ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Put a vendor block and a loose color after the group, to check ordering.
+	ase.Entries = append(ase.Entries, &RawBlock{Type: 0xbeef, Data: []byte("xyz")}, &ase.Colors[0])
+
+	out, err := json.Marshal(ase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPrefix := `{"version":"1.0","entries":[{"name":"RGB","model":"RGB","values":[1,1,1],"type":"Normal"},`
+	if !strings.HasPrefix(string(out), expectedPrefix) {
+		t.Error("unexpected JSON", string(out))
+	}
+	if !strings.Contains(string(out), `{"block":48879,"data":"eHl6"}`) {
+		t.Error("expected the raw block in", string(out))
+	}
+
+	var decoded ASE
+	if err = json.Unmarshal(out, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	// The ASE files they encode to are identical.
+	a, b := new(bytes.Buffer), new(bytes.Buffer)
+	if err = Encode(ase, a); err != nil {
+		t.Fatal(err)
+	}
+	if err = Encode(decoded, b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("JSON did not round-trip")
+	}
+
+	if len(decoded.Colors) != 6 || len(decoded.Groups) != 1 || len(decoded.Entries) != 8 {
+		t.Errorf("unexpected views: %d colors, %d groups, %d entries", len(decoded.Colors), len(decoded.Groups), len(decoded.Entries))
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var c Color
+	if err := json.Unmarshal([]byte(`{"name":"Teal","model":"rgb","values":[0,0.5,0.5],"type":"spot"}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "Teal" || c.Model != RGB || c.Type != Spot {
+		t.Errorf("unexpected color %+v", c)
+	}
+
+	var g Group
+	if err := json.Unmarshal([]byte(`{"name":"Empty","colors":[]}`), &g); err != nil || g.Name != "Empty" {
+		t.Errorf("unexpected group %+v, %v", g, err)
+	}
+
+	var ase ASE
+	for _, bad := range []string{
+		`{"version":"one","entries":[]}`,
+		`{"entries":[{"name":"x","model":"HSB"}]}`,
+		`{"entries":[{"name":"g","colors":[{"name":"h","colors":[]}]}]}`,
+	} {
+		if err := json.Unmarshal([]byte(bad), &ase); err == nil {
+			t.Error("expected an error for", bad)
+		}
+	}
+}
+
+func TestYAML(t *testing.T) {
+	ase, err := DecodeFile("samples/test.ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ase.Entries = append(ase.Entries, &RawBlock{Type: 0xbeef, Data: []byte("xyz")})
+
+	out, err := yaml.Marshal(ase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"version: \"1.0\"", "- name: A Color Group", "values: [1, 0, 0]", "block: 48879"} {
+		if !strings.Contains(string(out), s) {
+			t.Errorf("expected %s in\n%s", s, out)
+		}
+	}
+
+	var decoded ASE
+	if err = yaml.Unmarshal(out, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := new(bytes.Buffer), new(bytes.Buffer)
+	if err = Encode(ase, a); err != nil {
+		t.Fatal(err)
+	}
+	if err = Encode(decoded, b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("YAML did not round-trip")
+	}
+}
+
+func TestMarshalMalformedGroup(t *testing.T) {
+	data := malformedGroupFile(t)
+	ase, err := DecodeWithOptions(bytes.NewReader(data), DecoderOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codecs := []struct {
+		name      string
+		marshal   func(interface{}) ([]byte, error)
+		unmarshal func([]byte, interface{}) error
+	}{
+		{"JSON", json.Marshal, json.Unmarshal},
+		{"YAML", yaml.Marshal, yaml.Unmarshal},
+	}
+
+	for _, codec := range codecs {
+		out, err := codec.marshal(ase)
+		if err != nil {
+			t.Fatal(codec.name, err)
+		}
+
+		var decoded ASE
+		if err = codec.unmarshal(out, &decoded); err != nil {
+			t.Fatal(codec.name, err)
+		}
+
+		// The group's start and end blocks are written back as they were.
+		b := new(bytes.Buffer)
+		if err = Encode(decoded, b); err != nil {
+			t.Fatal(codec.name, err)
+		}
+		if !bytes.Equal(data, b.Bytes()) {
+			t.Errorf("%s did not round-trip the group's raw blocks:\n%s", codec.name, out)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package ase
+
+import (
+	"math"
+	"sort"
+)
+
+// A Matcher finds the swatches of a palette closest to any color.
+//
+// Swatches are indexed by their LAB values in a k-d tree, so lookups take
+// about logarithmic time in the size of the palette. Distances are CIE76
+// ones by default, see DeltaE76: in LAB, that's the distance the tree works
+// with. See MatcherOptions for perceptual ones.
+type Matcher struct {
+	swatches   []swatch // in tree order, see build
+	perceptual bool
+}
+
+// MatcherOptions controls how a Matcher measures distances.
+type MatcherOptions struct {
+	// Perceptual measures distances with DeltaE2000 instead of DeltaE76. As
+	// those don't follow the LAB axes the tree is split along, lookups then
+	// go through every swatch, taking linear time.
+	Perceptual bool
+}
+
+// A swatch of a Matcher.
+type swatch struct {
+	lab   [3]float64
+	color Color
+	group string
+	index int // in ASE.Palette, to break ties
+}
+
+// A Match is a swatch found by a Matcher.
+type Match struct {
+	Color Color
+
+	// Group is the name of the group the color belongs to, or empty for
+	// loose colors.
+	Group string
+
+	// Index is the position of the color in ASE.Palette.
+	Index int
+
+	// DeltaE is the distance between the color looked up and this one,
+	// CIE76 or CIEDE2000 depending on MatcherOptions.Perceptual.
+	DeltaE float64
+}
+
+// Returns a Matcher over every color of ase, loose or in groups. Colors that
+// can't be converted to LAB, see Validate, are left out.
+func NewMatcher(ase ASE) *Matcher {
+	return NewMatcherWithOptions(ase, MatcherOptions{})
+}
+
+// Returns a Matcher over every color of ase, measuring distances as set by
+// opts.
+func NewMatcherWithOptions(ase ASE, opts MatcherOptions) *Matcher {
+	m := &Matcher{perceptual: opts.Perceptual}
+
+	index := -1
+	ase.eachColor(func(group *Group, color *Color) {
+		index++
+		l, a, b, err := labOf(color)
+		if err != nil {
+			return
+		}
+
+		s := swatch{lab: [3]float64{l, a, b}, color: *copyColor(color), index: index}
+		if group != nil {
+			s.group = group.Name
+		}
+		m.swatches = append(m.swatches, s)
+	})
+
+	buildTree(m.swatches, 0)
+
+	return m
+}
+
+// Returns the number of swatches indexed.
+func (m *Matcher) Len() int {
+	return len(m.swatches)
+}
+
+// Returns the k swatches closest to c, closest first. Swatches as close as
+// each other are returned in document order. Fewer are returned when the
+// palette has less than k colors.
+func (m *Matcher) Nearest(c Color, k int) (matches []Match, err error) {
+	l, a, b, err := labOf(&c)
+	if err != nil || k <= 0 {
+		return
+	}
+
+	found := m.nearest([3]float64{l, a, b}, k)
+
+	matches = make([]Match, len(found))
+	for i, f := range found {
+		matches[i] = Match{Color: f.swatch.color, Group: f.swatch.group, Index: f.swatch.index, DeltaE: math.Sqrt(f.dist)}
+	}
+
+	return
+}
+
+// Returns the k swatches closest to a LAB color, closest first.
+func (m *Matcher) nearest(lab [3]float64, k int) []found {
+	s := nearestSearch{point: lab, k: k}
+	if !m.perceptual {
+		s.walk(m.swatches, 0)
+		return s.found
+	}
+
+	for i := range m.swatches {
+		node := &m.swatches[i]
+		s.add(node, sq(deltaE2000(lab[0], lab[1], lab[2], node.lab[0], node.lab[1], node.lab[2])))
+	}
+	return s.found
+}
+
+// Arranges swatches into a k-d tree: the median along axis sits in the middle,
+// with the swatches below it to the left and the ones above to the right, each
+// side arranged the same way along the next axis.
+func buildTree(swatches []swatch, axis int) {
+	if len(swatches) <= 1 {
+		return
+	}
+
+	sort.Slice(swatches, func(i, j int) bool {
+		return swatches[i].lab[axis] < swatches[j].lab[axis]
+	})
+
+	mid := len(swatches) / 2
+	next := (axis + 1) % 3
+	buildTree(swatches[:mid], next)
+	buildTree(swatches[mid+1:], next)
+}
+
+// A nearest neighbors lookup in progress.
+type nearestSearch struct {
+	point [3]float64
+	k     int
+	found []found // closest first
+}
+
+type found struct {
+	swatch *swatch
+	dist   float64 // squared
+}
+
+// Visits the tree, skipping the sides that can't hold anything closer than
+// what was found so far.
+func (s *nearestSearch) walk(swatches []swatch, axis int) {
+	if len(swatches) == 0 {
+		return
+	}
+
+	mid := len(swatches) / 2
+	node := &swatches[mid]
+	var dist float64
+	for i := range node.lab {
+		dist += sq(node.lab[i] - s.point[i])
+	}
+	s.add(node, dist)
+
+	near, far := swatches[:mid], swatches[mid+1:]
+	d := s.point[axis] - node.lab[axis]
+	if d > 0 {
+		near, far = far, near
+	}
+
+	next := (axis + 1) % 3
+	s.walk(near, next)
+
+	//	ties count, as they may come first in document order
+	if len(s.found) < s.k || d*d <= s.found[len(s.found)-1].dist {
+		s.walk(far, next)
+	}
+}
+
+// Keeps node, at squared distance dist, if it's among the k closest so far.
+func (s *nearestSearch) add(node *swatch, dist float64) {
+	closer := func(i int) bool {
+		f := s.found[i]
+		return dist < f.dist || (dist == f.dist && node.index < f.swatch.index)
+	}
+
+	i := sort.Search(len(s.found), closer)
+	if i == s.k {
+		return
+	}
+	if len(s.found) < s.k {
+		s.found = append(s.found, found{})
+	}
+	copy(s.found[i+1:], s.found[i:])
+	s.found[i] = found{swatch: node, dist: dist}
+}
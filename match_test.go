@@ -0,0 +1,131 @@
+package ase
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// Returns a palette of n random colors spread over groups of 100.
+func randomPalette(n int, rnd *rand.Rand) (ase ASE) {
+	for i := 0; i < n; i++ {
+		if i%100 == 0 {
+			ase.Groups = append(ase.Groups, Group{Name: fmt.Sprint("Group ", i/100)})
+		}
+		g := &ase.Groups[len(ase.Groups)-1]
+		g.Colors = append(g.Colors, Color{
+			Name:   fmt.Sprint("Color ", i),
+			Model:  RGB,
+			Values: []float32{rnd.Float32(), rnd.Float32(), rnd.Float32()},
+			Type:   Spot,
+		})
+	}
+	return
+}
+
+func TestMatcher(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	palette := randomPalette(3000, rnd)
+
+	// Duplicates tie and come back in document order.
+	palette.Colors = []Color{{Name: "Dup 1", Model: RGB, Values: []float32{0.5, 0.5, 0.5}, Type: Normal}}
+	palette.Groups[29].Colors = append(palette.Groups[29].Colors,
+		Color{Name: "Dup 2", Model: Gray, Values: []float32{0.5}, Type: Normal},
+		Color{Name: "Broken", Model: RGB, Values: []float32{1}, Type: Normal},
+	)
+
+	m := NewMatcher(palette)
+	if m.Len() != 3002 {
+		t.Fatal("expected 3002 swatches, got", m.Len())
+	}
+
+	matches, err := m.Nearest(Color{Model: LAB, Values: []float32{0.5, 0, 0}}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[0].Color.Name != "Dup 1" || matches[0].Group != "" ||
+		matches[1].Color.Name != "Dup 2" || matches[1].Group != "Group 29" || matches[0].DeltaE != matches[1].DeltaE ||
+		matches[0].Index != 0 || matches[1].Index != 3001 {
+		t.Error("unexpected matches", matches)
+	}
+
+	// Same as checking every swatch.
+	var all []Match
+	palette.eachColor(func(group *Group, color *Color) {
+		if color.checkValues() == nil {
+			all = append(all, Match{Color: *color})
+		}
+	})
+
+	for i := 0; i < 200; i++ {
+		c := Color{Model: CMYK, Values: []float32{rnd.Float32(), rnd.Float32(), rnd.Float32(), rnd.Float32() / 2}}
+		k := 1 + i%7
+
+		for j := range all {
+			all[j].DeltaE, _ = DeltaE76(c, all[j].Color)
+		}
+		sort.SliceStable(all, func(a, b int) bool { return all[a].DeltaE < all[b].DeltaE })
+
+		matches, err := m.Nearest(c, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) != k {
+			t.Fatal("expected", k, "matches, got", len(matches))
+		}
+		for j := range matches {
+			if math.Abs(matches[j].DeltaE-all[j].DeltaE) > 1e-9 {
+				t.Fatalf("lookup %d, match %d: expected %s at %f, got %s at %f",
+					i, j, all[j].Color.Name, all[j].DeltaE, matches[j].Color.Name, matches[j].DeltaE)
+			}
+		}
+	}
+
+	// Asking for more than there is.
+	small := NewMatcher(ASE{Colors: palette.Colors})
+	if matches, _ := small.Nearest(palette.Colors[0], 5); len(matches) != 1 {
+		t.Error("expected 1 match, got", matches)
+	}
+	if _, err := small.Nearest(Color{Model: RGB}, 1); err == nil {
+		t.Error("expected an error for a color without values")
+	}
+}
+
+func TestMatcherPerceptual(t *testing.T) {
+	palette := ASE{Colors: []Color{
+		{Name: "Black", Model: Gray, Values: []float32{0}, Type: Normal},
+		{Name: "White", Model: Gray, Values: []float32{1}, Type: Normal},
+		{Name: "Red", Model: RGB, Values: []float32{1, 0, 0}, Type: Normal},
+	}}
+	gray := Color{Model: RGB, Values: []float32{0.5, 0.5, 0.5}}
+
+	// CIEDE2000 weighs the chroma difference less than CIE76 does.
+	matches, _ := NewMatcher(palette).Nearest(gray, 1)
+	if len(matches) != 1 || matches[0].Color.Name != "White" {
+		t.Error("expected White by CIE76, got", matches)
+	}
+
+	m := NewMatcherWithOptions(palette, MatcherOptions{Perceptual: true})
+	matches, err := m.Nearest(gray, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := DeltaE2000(gray, palette.Colors[2])
+	if len(matches) != 3 || matches[0].Color.Name != "Red" || matches[1].Color.Name != "White" ||
+		matches[2].Color.Name != "Black" || math.Abs(matches[0].DeltaE-expected) > 1e-9 {
+		t.Error("unexpected matches", matches)
+	}
+}
+
+func BenchmarkMatcher(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	m := NewMatcher(randomPalette(5000, rnd))
+	c := Color{Model: RGB, Values: []float32{0.2, 0.4, 0.6}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Nearest(c, 5)
+	}
+}
@@ -0,0 +1,160 @@
+package ase
+
+import (
+	"errors"
+	"image"
+	imagecolor "image/color"
+	"image/draw"
+)
+
+var (
+	ErrEmptyPalette  = errors.New("ase: palette has no usable colors")
+	ErrTooManyColors = errors.New("ase: palette has more than 256 colors")
+)
+
+// RecolorOptions controls how Recolor and Drawer map pixels to swatches.
+type RecolorOptions struct {
+	// Dither spreads the difference between each pixel and its swatch over
+	// the next pixels, through draw.FloydSteinberg. Gradients come out
+	// smoother, flat areas noisier.
+	Dither bool
+}
+
+// Returns img with every pixel replaced by the closest color of palette, as
+// drawn by a Drawer. The result's palette is ASE.Palette converted to 8 bit
+// sRGB, so it can be encoded as an indexed PNG or GIF, and the indexes of
+// colors that can't be converted are never used.
+//
+// Pixels less than half opaque are mapped to an extra, fully transparent,
+// color at the end of the palette. As indexes only go up to 255, the palette
+// can't have more than 256 colors, transparent one included.
+func Recolor(img image.Image, palette ASE, opts RecolorOptions) (*image.Paletted, error) {
+	bounds := img.Bounds()
+	transparent := false
+	for y := bounds.Min.Y; y < bounds.Max.Y && !transparent; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a < 0x8000 {
+				transparent = true
+				break
+			}
+		}
+	}
+
+	d, err := newDrawer(palette, opts, transparent)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.palette) > 256 {
+		return nil, ErrTooManyColors
+	}
+
+	dst := image.NewPaletted(bounds, d.palette)
+	d.Draw(dst, bounds, img, bounds.Min)
+
+	return dst, nil
+}
+
+// A Drawer draws images in the colors of a palette, each pixel taking the
+// swatch closest to it by DeltaE2000. It's a draw.Drawer, going through
+// draw.FloydSteinberg when dithering and draw.Src otherwise, so it can be
+// used wherever those are.
+//
+// Pixels less than half opaque take the fully transparent color at the end
+// of Palette.
+type Drawer struct {
+	palette     imagecolor.Palette
+	transparent bool
+	dither      bool
+	matcher     *Matcher
+	cache       map[[3]uint8]int // by 8 bit sRGB color
+}
+
+// Returns a Drawer using the colors of palette, or ErrEmptyPalette when none
+// of them can be converted to sRGB.
+func NewDrawer(palette ASE, opts RecolorOptions) (*Drawer, error) {
+	return newDrawer(palette, opts, true)
+}
+
+func newDrawer(palette ASE, opts RecolorOptions, transparent bool) (*Drawer, error) {
+	m := NewMatcherWithOptions(palette, MatcherOptions{Perceptual: true})
+	if m.Len() == 0 {
+		return nil, ErrEmptyPalette
+	}
+
+	colors := palette.Palette()
+	p := make(imagecolor.Palette, len(colors), len(colors)+1)
+	for i, c := range colors {
+		p[i] = imagecolor.NRGBAModel.Convert(c)
+	}
+	if transparent {
+		p = append(p, imagecolor.NRGBA{})
+	}
+
+	return &Drawer{palette: p, transparent: transparent, dither: opts.Dither, matcher: m, cache: map[[3]uint8]int{}}, nil
+}
+
+// Returns the colors the Drawer draws with: ASE.Palette converted to 8 bit
+// sRGB, followed by a fully transparent color. Colors that can't be converted
+// are never used.
+func (d *Drawer) Palette() imagecolor.Palette {
+	return d.palette
+}
+
+// Draws the r part of dst with src, starting from sp, in the colors of the
+// palette. When dst is an *image.Paletted, its palette must be Palette, as
+// the indexes are set directly.
+func (d *Drawer) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	var drawer draw.Drawer = draw.Src
+	if d.dither {
+		drawer = draw.FloydSteinberg
+	}
+	drawer.Draw(&drawerImage{Image: dst, drawer: d}, r, src, sp)
+}
+
+// Returns the palette index of the swatch closest to c.
+func (d *Drawer) index(c imagecolor.Color) int {
+	r, g, b, a := c.RGBA()
+	if a < 0x8000 && d.transparent {
+		return len(d.palette) - 1
+	}
+
+	//	dithering can push the colors out of range
+	key := [3]uint8{unpremultiply(r, a), unpremultiply(g, a), unpremultiply(b, a)}
+	if index, ok := d.cache[key]; ok {
+		return index
+	}
+
+	l, la, lb := rgbToLab(float64(key[0])/0xff, float64(key[1])/0xff, float64(key[2])/0xff)
+	index := d.matcher.nearest([3]float64{l, la, lb}, 1)[0].swatch.index
+	d.cache[key] = index
+
+	return index
+}
+
+// Returns the 8 bit value of a 16 bit channel premultiplied by alpha a.
+func unpremultiply(v, a uint32) uint8 {
+	if a == 0 {
+		return 0
+	}
+	if v >= a {
+		return 0xff
+	}
+	return uint8((v*0xff + a/2) / a)
+}
+
+// The image a Drawer has image/draw draw on: colors set on it are replaced
+// by their swatch, which is what Floyd-Steinberg takes the difference from
+// when reading them back.
+type drawerImage struct {
+	draw.Image
+	drawer *Drawer
+}
+
+func (img *drawerImage) Set(x, y int, c imagecolor.Color) {
+	index := img.drawer.index(c)
+	if p, ok := img.Image.(*image.Paletted); ok {
+		p.SetColorIndex(x, y, uint8(index))
+		return
+	}
+	img.Image.Set(x, y, img.drawer.palette[index])
+}
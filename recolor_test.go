@@ -0,0 +1,147 @@
+package ase
+
+import (
+	"errors"
+	"image"
+	imagecolor "image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestRecolor(t *testing.T) {
+	palette := ASE{
+		Colors: []Color{
+			{Name: "Black", Model: Gray, Values: []float32{0}, Type: Normal},
+			{Name: "Broken", Model: RGB, Values: []float32{1}, Type: Normal},
+		},
+		Groups: []Group{{Name: "Brand", Colors: []Color{
+			{Name: "White", Model: CMYK, Values: []float32{0, 0, 0, 0}, Type: Spot},
+			{Name: "Red", Model: RGB, Values: []float32{1, 0, 0}, Type: Spot},
+		}}},
+	}
+
+	img := image.NewNRGBA(image.Rect(2, 3, 6, 4))
+	img.SetNRGBA(2, 3, imagecolor.NRGBA{0x20, 0x10, 0x10, 0xff})
+	img.SetNRGBA(3, 3, imagecolor.NRGBA{0xf0, 0xf0, 0xe0, 0xff})
+	img.SetNRGBA(4, 3, imagecolor.NRGBA{0xd0, 0x30, 0x20, 0xff})
+	img.SetNRGBA(5, 3, imagecolor.NRGBA{0xd0, 0x30, 0x20, 0x10})
+
+	dst, err := Recolor(img, palette, RecolorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dst.Bounds() != img.Bounds() || len(dst.Palette) != 5 {
+		t.Fatal("unexpected result", dst.Bounds(), dst.Palette)
+	}
+	if dst.Palette[2] != (imagecolor.NRGBA{0xff, 0xff, 0xff, 0xff}) || dst.Palette[4] != (imagecolor.NRGBA{}) {
+		t.Error("unexpected palette", dst.Palette)
+	}
+	expected := []uint8{0, 2, 3, 4}
+	for i, index := range expected {
+		if got := dst.ColorIndexAt(2+i, 3); got != index {
+			t.Errorf("pixel %d: expected index %d, got %d", i, index, got)
+		}
+	}
+
+	// Mid gray between black and white. Only those two are offered, as by
+	// DeltaE2000 the grays dithering goes through are closer to red.
+	gray := image.NewGray(image.Rect(0, 0, 32, 32))
+	for i := range gray.Pix {
+		gray.Pix[i] = 0xbc // 0.5 in linear light
+	}
+	blackWhite := ASE{Colors: []Color{palette.Colors[0], palette.Groups[0].Colors[0]}}
+
+	for _, dither := range []bool{false, true} {
+		dst, err = Recolor(gray, blackWhite, RecolorOptions{Dither: dither})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		white := 0
+		for _, index := range dst.Pix {
+			if index == 1 {
+				white++
+			} else if index != 0 {
+				t.Fatal("expected only black and white, got", index)
+			}
+		}
+
+		if !dither && white != len(dst.Pix) {
+			t.Error("expected all white without dithering, got", white)
+		}
+		if dither && (white < 400 || white > 800) {
+			t.Error("expected about half white with dithering, got", white)
+		}
+	}
+
+	if _, err = Recolor(img, ASE{Colors: palette.Colors[1:]}, RecolorOptions{}); !errors.Is(err, ErrEmptyPalette) {
+		t.Error("expected ErrEmptyPalette, got", err)
+	}
+
+	var big ASE
+	for i := 0; i < 256; i++ {
+		big.Colors = append(big.Colors, Color{Name: "Gray", Model: Gray, Values: []float32{float32(i) / 255}, Type: Normal})
+	}
+	if _, err = Recolor(gray, big, RecolorOptions{}); err != nil {
+		t.Error("expected 256 colors to work, got", err)
+	}
+	if _, err = Recolor(img, big, RecolorOptions{}); !errors.Is(err, ErrTooManyColors) {
+		t.Error("expected ErrTooManyColors with transparency, got", err)
+	}
+}
+
+func TestDrawer(t *testing.T) {
+	palette := ASE{Colors: []Color{
+		{Name: "Black", Model: Gray, Values: []float32{0}, Type: Normal},
+		{Name: "White", Model: Gray, Values: []float32{1}, Type: Normal},
+		{Name: "Red", Model: RGB, Values: []float32{1, 0, 0}, Type: Normal},
+	}}
+
+	d, err := NewDrawer(palette, RecolorOptions{Dither: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := d.Palette(); len(p) != 4 || p[3] != (imagecolor.NRGBA{}) {
+		t.Fatal("unexpected palette", p)
+	}
+
+	// Onto any image, through the draw.Drawer interface.
+	src := image.NewNRGBA(image.Rect(0, 0, 16, 4))
+	for x := 0; x < 16; x++ {
+		src.SetNRGBA(x, 0, imagecolor.NRGBA{0x80, 0x80, 0x80, 0xff})
+		src.SetNRGBA(x, 1, imagecolor.NRGBA{uint8(x * 0x11), 0, 0, 0xff})
+		src.SetNRGBA(x, 2, imagecolor.NRGBA{0xff, 0xff, 0xff, 0x10})
+	}
+	dst := image.NewNRGBA(image.Rect(10, 10, 26, 14))
+	var drawer draw.Drawer = d
+	drawer.Draw(dst, dst.Bounds(), src, image.Point{})
+
+	transparent := 0
+	for y := 10; y < 14; y++ {
+		for x := 10; x < 26; x++ {
+			c := dst.NRGBAAt(x, y)
+			if p := d.Palette(); p[p.Index(c)] != c {
+				t.Fatal("expected only palette colors, got", c)
+			}
+			if c.A == 0 {
+				transparent++
+			}
+		}
+	}
+	if transparent != 32 {
+		t.Error("expected the last two rows to be transparent, got", transparent)
+	}
+
+	red := imagecolor.NRGBA{0xff, 0, 0, 0xff}
+
+	// The first pixel of a gray row is matched before any difference
+	// spreads to it.
+	if c := dst.NRGBAAt(10, 10); c != red {
+		t.Error("expected mid gray to be red by DeltaE2000, got", c)
+	}
+
+	if _, err := NewDrawer(ASE{}, RecolorOptions{}); !errors.Is(err, ErrEmptyPalette) {
+		t.Error("expected ErrEmptyPalette, got", err)
+	}
+}
@@ -0,0 +1,264 @@
+package ase
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image"
+	imagecolor "image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// RenderOptions controls the layout of swatch sheets.
+type RenderOptions struct {
+	// Columns is the most chips per row, 8 when 0. Longer groups wrap.
+	Columns int
+
+	// Scale multiplies the size of everything, 1 when 0. At 1, chips are
+	// 120 by 72 pixels.
+	Scale int
+}
+
+// Layout of a sheet, in unscaled pixels.
+const (
+	sheetMargin = 16
+	chipWidth   = 120
+	chipHeight  = 72
+	chipGap     = 12
+	lineHeight  = 10
+	labelGap    = 6
+	sectionGap  = 16
+	titleHeight = lineHeight + 6
+
+	// chips and their three lines of labels
+	cellHeight = chipHeight + labelGap + 3*lineHeight
+
+	// characters fitting under a chip
+	labelChars = chipWidth / glyphWidth
+)
+
+var (
+	sheetBackground = imagecolor.NRGBA{0xff, 0xff, 0xff, 0xff}
+	sheetText       = imagecolor.NRGBA{0x33, 0x33, 0x33, 0xff}
+	sheetBorder     = imagecolor.NRGBA{0xcc, 0xcc, 0xcc, 0xff}
+)
+
+// A swatch sheet laid out, for RenderImage and RenderSVG to draw.
+type sheet struct {
+	width, height int
+	chips         []sheetChip
+	texts         []sheetLabel
+}
+
+// A chip, filled with fill unless the color couldn't be converted to sRGB.
+type sheetChip struct {
+	x, y int
+	fill imagecolor.NRGBA
+	ok   bool
+}
+
+// A line of text, x and y being its top left corner.
+type sheetLabel struct {
+	x, y  int
+	text  string
+	title bool
+}
+
+// A run of colors shown together: a group's, or loose colors between groups.
+type section struct {
+	group  *Group // nil for loose colors
+	colors []*Color
+}
+
+// Splits the colors Encode would write into sections, in order.
+func (ase *ASE) sections() (sections []section) {
+	for _, entry := range ase.entries() {
+		switch entry := entry.(type) {
+		case *Color:
+			if len(sections) == 0 || sections[len(sections)-1].group != nil {
+				sections = append(sections, section{})
+			}
+			last := &sections[len(sections)-1]
+			last.colors = append(last.colors, entry)
+		case *Group:
+			sec := section{group: entry}
+			entry.eachColor(func(group *Group, color *Color) {
+				sec.colors = append(sec.colors, color)
+			})
+			sections = append(sections, sec)
+		}
+	}
+	return
+}
+
+// Lays out every color Encode would write. Groups get a title and rows of
+// their own, and loose colors between groups share untitled rows.
+func layoutSheet(ase *ASE, opts RenderOptions) (s sheet) {
+	columns := opts.Columns
+	if columns <= 0 {
+		columns = 8
+	}
+
+	sections := ase.sections()
+
+	//	as wide as the longest row
+	used := 1
+	for _, sec := range sections {
+		if n := len(sec.colors); n > used {
+			used = n
+		}
+	}
+	if used > columns {
+		used = columns
+	}
+	s.width = 2*sheetMargin + used*chipWidth + (used-1)*chipGap
+
+	y := sheetMargin
+	for _, sec := range sections {
+		if sec.group != nil {
+			chars := (s.width - 2*sheetMargin) / glyphWidth
+			s.texts = append(s.texts, sheetLabel{x: sheetMargin, y: y, text: truncate(sec.group.Name, chars), title: true})
+			y += titleHeight
+		}
+
+		for i, color := range sec.colors {
+			col := i % columns
+			if col == 0 && i > 0 {
+				y += cellHeight + chipGap
+			}
+			x := sheetMargin + col*(chipWidth+chipGap)
+
+			chip := sheetChip{x: x, y: y}
+			if color.checkValues() == nil {
+				r, g, b := color.rgb(NaiveCMYK{})
+				chip.fill = imagecolor.NRGBA{to8(r), to8(g), to8(b), 0xff}
+				chip.ok = true
+			}
+			s.chips = append(s.chips, chip)
+
+			values := make([]string, len(color.Values))
+			for j, v := range color.Values {
+				values[j] = formatNumber(float64(v), 2)
+			}
+
+			ly := y + chipHeight + labelGap
+			for j, text := range []string{
+				color.Name,
+				color.Model.String() + " " + color.Type.String(),
+				strings.Join(values, " "),
+			} {
+				s.texts = append(s.texts, sheetLabel{x: x, y: ly + j*lineHeight, text: truncate(text, labelChars)})
+			}
+		}
+
+		if len(sec.colors) > 0 {
+			y += cellHeight
+		}
+		y += sectionGap
+	}
+
+	s.height = y - sectionGap + sheetMargin
+	if len(sections) == 0 {
+		s.height = 2 * sheetMargin
+	}
+
+	return
+}
+
+// Cuts text down to n characters, ending it with ".." when it's too long.
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-2]) + ".."
+}
+
+// Draws a swatch sheet of every group and color: a labelled row of chips per
+// group, each with the color's name, model, type and values. Colors are shown
+// converted to sRGB, and colors that can't be converted are left empty. The
+// labels only have ASCII characters, others show as '?'.
+func RenderImage(ase ASE, opts RenderOptions) *image.NRGBA {
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+
+	s := layoutSheet(&ase, opts)
+	img := image.NewNRGBA(image.Rect(0, 0, s.width*scale, s.height*scale))
+	fillRect(img, img.Bounds(), sheetBackground)
+
+	for _, chip := range s.chips {
+		r := image.Rect(chip.x, chip.y, chip.x+chipWidth, chip.y+chipHeight)
+		r.Min, r.Max = r.Min.Mul(scale), r.Max.Mul(scale)
+
+		//	a border keeps light chips apart from the background
+		fillRect(img, r, sheetBorder)
+		if chip.ok {
+			fillRect(img, r.Inset(scale), chip.fill)
+		} else {
+			fillRect(img, r.Inset(scale), sheetBackground)
+		}
+	}
+
+	for _, label := range s.texts {
+		drawText(img, label.x*scale, label.y*scale, scale, label.text, sheetText)
+	}
+
+	return img
+}
+
+// Encodes the sheet drawn by RenderImage as a PNG.
+func RenderPNG(ase ASE, w io.Writer, opts RenderOptions) error {
+	return png.Encode(w, RenderImage(ase, opts))
+}
+
+// Encodes a swatch sheet laid out like RenderImage's as an SVG document. Text
+// is left as text, so labels keep every character.
+func RenderSVG(ase ASE, w io.Writer, opts RenderOptions) (err error) {
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+
+	s := layoutSheet(&ase, opts)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="9">`+"\n",
+		s.width*scale, s.height*scale, s.width, s.height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="%s"/>`+"\n", s.width, s.height, hex8(sheetBackground))
+
+	for _, chip := range s.chips {
+		fill := "none"
+		if chip.ok {
+			fill = hex8(chip.fill)
+		}
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="%s"/>`+"\n",
+			chip.x, chip.y, chipWidth, chipHeight, fill, hex8(sheetBorder))
+	}
+
+	for _, label := range s.texts {
+		weight := ""
+		if label.title {
+			weight = ` font-weight="bold"`
+		}
+
+		//	text is placed by its baseline, at the bottom of the glyphs
+		fmt.Fprintf(bw, `<text x="%d" y="%d" fill="%s"%s>`, label.x, label.y+glyphRows, hex8(sheetText), weight)
+		if err = xml.EscapeText(bw, []byte(label.text)); err != nil {
+			return
+		}
+		fmt.Fprint(bw, "</text>\n")
+	}
+
+	fmt.Fprint(bw, "</svg>\n")
+
+	return bw.Flush()
+}
+
+// Returns c as "#RRGGBB".
+func hex8(c imagecolor.NRGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
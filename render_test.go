@@ -0,0 +1,105 @@
+package ase
+
+import (
+	"bytes"
+	"encoding/xml"
+	imagecolor "image/color"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+func testSheet() ASE {
+	return ASE{
+		Colors: []Color{
+			{Name: "Red", Model: RGB, Values: []float32{1, 0, 0}, Type: Global},
+			{Name: "Broken", Model: RGB, Values: []float32{1}, Type: Normal},
+		},
+		Groups: []Group{{Name: "Brand <&>", Colors: []Color{
+			{Name: "Black", Model: CMYK, Values: []float32{0, 0, 0, 1}, Type: Spot},
+			{Name: "Gray", Model: Gray, Values: []float32{0.5}, Type: Normal},
+			{Name: "A name much too long to fit", Model: LAB, Values: []float32{0.5, 20, -30}, Type: Normal},
+		}}},
+	}
+}
+
+func TestRenderImage(t *testing.T) {
+	img := RenderImage(testSheet(), RenderOptions{Columns: 2, Scale: 2})
+
+	// Two columns, loose colors on one row, then a title and two rows.
+	width := 2 * (2*sheetMargin + 2*chipWidth + chipGap)
+	height := 2 * (2*sheetMargin + 3*cellHeight + chipGap + sectionGap + titleHeight)
+	if b := img.Bounds(); b.Dx() != width || b.Dy() != height {
+		t.Fatal("expected", width, "by", height, "got", b)
+	}
+
+	at := func(x, y int) imagecolor.NRGBA {
+		return img.NRGBAAt(x*2, y*2)
+	}
+
+	// Chips are filled inside their border.
+	x, y := sheetMargin+chipWidth/2, sheetMargin+chipHeight/2
+	if c := at(x, y); c != (imagecolor.NRGBA{0xff, 0, 0, 0xff}) {
+		t.Error("expected red, got", c)
+	}
+	if c := at(sheetMargin, sheetMargin); c != sheetBorder {
+		t.Error("expected the border, got", c)
+	}
+	if c := at(x+chipWidth+chipGap, y); c != sheetBackground {
+		t.Error("expected the broken color to be empty, got", c)
+	}
+	y += cellHeight + sectionGap + titleHeight
+	if c := at(x+chipWidth+chipGap, y); c != (imagecolor.NRGBA{0x80, 0x80, 0x80, 0xff}) {
+		t.Error("expected gray, got", c)
+	}
+
+	// The first label row has the name, "Red" starting with the R's stem.
+	ly := sheetMargin + chipHeight + labelGap
+	if c := at(sheetMargin, ly); c != sheetText {
+		t.Error("expected text, got", c)
+	}
+
+	var b bytes.Buffer
+	if err := RenderPNG(testSheet(), &b, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := png.Decode(&b); err != nil || decoded.Bounds().Dx() != 2*sheetMargin+3*chipWidth+2*chipGap {
+		t.Error("unexpected PNG", err)
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	var b bytes.Buffer
+	if err := RenderSVG(testSheet(), &b, RenderOptions{Scale: 2}); err != nil {
+		t.Fatal(err)
+	}
+	svg := b.String()
+
+	for _, s := range []string{
+		`width="832" height="560"`,
+		`viewBox="0 0 416 280"`,
+		`<rect x="16" y="16" width="120" height="72" fill="#FF0000" stroke="#CCCCCC"/>`,
+		`fill="none"`,
+		`font-weight="bold">Brand &lt;&amp;&gt;</text>`,
+		`>A name much too lo..</text>`,
+		`>CMYK Spot</text>`,
+		`>0.5 20 -30</text>`,
+	} {
+		if !strings.Contains(svg, s) {
+			t.Errorf("expected %q in\n%s", s, svg)
+		}
+	}
+
+	// It's well formed.
+	d := xml.NewDecoder(&b)
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+package ase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidTokens = errors.New("ase: invalid design tokens")
+
+// The $extensions key holding what design tokens can't express about a color.
+const tokensExtension = "com.github.arolek.ase"
+
+// What a color token's $extensions keep of the ASE color.
+type tokenExtension struct {
+	Name   string     `json:"name,omitempty"`   // when the token name had to be changed
+	Model  ColorModel `json:"model,omitempty"`  // for CMYK and Gray, whose $value is converted
+	Values []float32  `json:"values,omitempty"` // along with Model
+	Type   ColorType  `json:"type"`
+}
+
+// The $value of a color token.
+type tokenColor struct {
+	ColorSpace string        `json:"colorSpace"`
+	Components []interface{} `json:"components"`
+	Alpha      *float64      `json:"alpha,omitempty"`
+	Hex        string        `json:"hex,omitempty"`
+}
+
+// Encodes an ASE as W3C Design Tokens JSON.
+//
+// Loose colors become top level color tokens and each group a token group.
+// RGB colors are written in the srgb color space and LAB colors in lab.
+// CMYK and Gray colors are converted to srgb, their original model and values
+// are kept in $extensions along with the ASE type. Token names can't contain
+// '.', '{' or '}' nor start with '$', those are replaced, and names used twice
+// in a group get a number. The original name goes in $extensions too.
+func EncodeTokens(ase ASE, w io.Writer) (err error) {
+	var root tokenObject
+	names := map[string]bool{}
+
+	for _, entry := range ase.entries() {
+		switch entry := entry.(type) {
+		case *Color:
+			var token tokenObject
+			if token, err = colorToken(entry); err != nil {
+				return
+			}
+			root = append(root, tokenMember{tokenName(entry.Name, names), token})
+		case *Group:
+			var group tokenObject
+			groupNames := map[string]bool{}
+			entry.eachColor(func(_ *Group, color *Color) {
+				if err != nil {
+					return
+				}
+				var token tokenObject
+				if token, err = colorToken(color); err == nil {
+					group = append(group, tokenMember{tokenName(color.Name, groupNames), token})
+				}
+			})
+			if err != nil {
+				return
+			}
+			root = append(root, tokenMember{tokenName(entry.Name, names), group})
+		}
+	}
+
+	out, err := json.Marshal(root)
+	if err != nil {
+		return
+	}
+
+	var b bytes.Buffer
+	if err = json.Indent(&b, out, "", "  "); err != nil {
+		return
+	}
+	b.WriteByte('\n')
+	_, err = b.WriteTo(w)
+
+	return
+}
+
+// Decodes W3C Design Tokens JSON into an ASE.
+//
+// Color tokens at the top level become loose colors. Those inside groups go
+// into an ASE group named after the path to them, such as "Brand/Primary",
+// since ASE groups can't be nested. Tokens of other types are skipped. A
+// $value can be a color object, a CSS color string or a reference to another
+// color token such as "{brand.red}". The ASE model, values, type and name are
+// taken from $extensions when EncodeTokens put them there, otherwise colors
+// are of type Normal. Entries lists the loose colors and groups in document
+// order.
+func DecodeTokens(r io.Reader) (ase ASE, err error) {
+	var raw json.RawMessage
+	if err = json.NewDecoder(r).Decode(&raw); err != nil {
+		return
+	}
+
+	root, err := parseTokenObject(raw)
+	if err != nil {
+		return
+	}
+
+	d := tokenDecoder{tokens: map[string]tokenObject{}}
+	d.index(root, "")
+
+	var entries []Entry
+	if err = d.walk(&entries, root, nil, ""); err != nil {
+		return
+	}
+
+	//	empty groups only come from groups holding nothing but other groups
+	kept := entries[:0]
+	for _, entry := range entries {
+		if g, ok := entry.(*Group); ok && len(g.Colors) == 0 {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	ase.fill(kept)
+
+	return
+}
+
+// A tokenObject is a JSON object that keeps the order of its members.
+type tokenObject []tokenMember
+
+type tokenMember struct {
+	Key   string
+	Value interface{}
+}
+
+func (obj tokenObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, m := range obj {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(m.Key)
+		value, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Returns the member named key, or nil.
+func (obj tokenObject) get(key string) interface{} {
+	for _, m := range obj {
+		if m.Key == key {
+			return m.Value
+		}
+	}
+	return nil
+}
+
+// Parses a JSON object, keeping the order of its members. Nested groups and
+// tokens are parsed too, other values are kept raw.
+func parseTokenObject(raw json.RawMessage) (obj tokenObject, err error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("%w: expected an object", ErrInvalidTokens)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string)
+
+		var value json.RawMessage
+		if err = dec.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		//	$ properties stay raw, whatever their value
+		member := tokenMember{Key: key, Value: value}
+		if !strings.HasPrefix(key, "$") && bytes.HasPrefix(bytes.TrimSpace(value), []byte("{")) {
+			if member.Value, err = parseTokenObject(value); err != nil {
+				return nil, err
+			}
+		}
+		obj = append(obj, member)
+	}
+
+	return
+}
+
+// Builds the token for a color.
+func colorToken(color *Color) (token tokenObject, err error) {
+	if err = color.checkValues(); err != nil {
+		return nil, fmt.Errorf("ase: color %q: %w", color.Name, err)
+	}
+
+	ext := tokenExtension{Type: color.Type}
+	if tokenName(color.Name, nil) != color.Name {
+		ext.Name = color.Name
+	}
+
+	hex, _ := color.Hex()
+	value := tokenColor{Hex: strings.ToLower(hex)}
+
+	switch color.Model {
+	case LAB:
+		value.ColorSpace = "lab"
+		value.Components = []interface{}{color.Values[0] * 100, color.Values[1], color.Values[2]}
+	case RGB:
+		value.ColorSpace = "srgb"
+		value.Components = []interface{}{color.Values[0], color.Values[1], color.Values[2]}
+	default:
+		r, g, b := color.rgb(NaiveCMYK{})
+		value.ColorSpace = "srgb"
+		value.Components = []interface{}{float32(r), float32(g), float32(b)}
+		ext.Model = color.Model
+		ext.Values = color.Values
+	}
+
+	return tokenObject{
+		{"$type", "color"},
+		{"$value", value},
+		{"$extensions", map[string]tokenExtension{tokensExtension: ext}},
+	}, nil
+}
+
+// Returns name made into a valid token name. When names is given, names
+// already in it get a number and the result is added to it.
+func tokenName(name string, names map[string]bool) string {
+	name = strings.NewReplacer(".", "_", "{", "(", "}", ")").Replace(name)
+	if strings.HasPrefix(name, "$") || name == "" {
+		name = "_" + name
+	}
+	if names == nil {
+		return name
+	}
+
+	unique := name
+	for i := 2; names[unique]; i++ {
+		unique = name + " " + strconv.Itoa(i)
+	}
+	names[unique] = true
+
+	return unique
+}
+
+// Turns a tree of design tokens into colors and groups.
+type tokenDecoder struct {
+	tokens    map[string]tokenObject // every token by its dotted path
+	resolving map[string]bool        // references being followed, to catch cycles
+}
+
+// Records the tokens of obj, whose path is prefix.
+func (d *tokenDecoder) index(obj tokenObject, prefix string) {
+	for _, m := range obj {
+		child, ok := m.Value.(tokenObject)
+		if !ok || strings.HasPrefix(m.Key, "$") {
+			continue
+		}
+		if child.get("$value") != nil {
+			d.tokens[prefix+m.Key] = child
+		} else {
+			d.index(child, prefix+m.Key+".")
+		}
+	}
+}
+
+// Adds the color tokens of obj to group, or to entries when group is nil, and
+// the groups inside it to entries, in document order. path is the names of
+// obj and the groups around it.
+func (d *tokenDecoder) walk(entries *[]Entry, obj tokenObject, group *Group, path string) error {
+	//	the $type of the enclosing groups
+	inherited := ""
+	if t, ok := obj.get("$type").(json.RawMessage); ok {
+		if err := json.Unmarshal(t, &inherited); err != nil {
+			return fmt.Errorf("%w: group %q: $type: %v", ErrInvalidTokens, path, err)
+		}
+	}
+
+	for _, m := range obj {
+		child, ok := m.Value.(tokenObject)
+		if !ok || strings.HasPrefix(m.Key, "$") {
+			continue
+		}
+
+		//	groups
+		if child.get("$value") == nil {
+			name := m.Key
+			if path != "" {
+				name = path + "/" + m.Key
+			}
+
+			if _, ok := child.get("$type").(json.RawMessage); !ok && inherited != "" {
+				child = append(tokenObject{{"$type", json.RawMessage(strconv.Quote(inherited))}}, child...)
+			}
+
+			//	a group's own colors come before those of the groups inside it
+			g := &Group{Name: name}
+			*entries = append(*entries, g)
+			if err := d.walk(entries, child, g, name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tokenType := inherited
+		if t, ok := child.get("$type").(json.RawMessage); ok {
+			if err := json.Unmarshal(t, &tokenType); err != nil {
+				return fmt.Errorf("%w: token %q: $type: %v", ErrInvalidTokens, m.Key, err)
+			}
+		}
+		if tokenType != "color" {
+			continue
+		}
+
+		color, err := d.color(child, m.Key)
+		if err != nil {
+			return fmt.Errorf("%w: token %q: %v", ErrInvalidTokens, m.Key, err)
+		}
+
+		if group != nil {
+			group.Colors = append(group.Colors, color)
+		} else {
+			*entries = append(*entries, &color)
+		}
+	}
+
+	return nil
+}
+
+// Converts a color token named name.
+func (d *tokenDecoder) color(token tokenObject, name string) (color Color, err error) {
+	raw, _ := token.get("$value").(json.RawMessage)
+	if raw == nil {
+		return color, errors.New("$value is not a color")
+	}
+
+	if color, err = d.value(raw); err != nil {
+		return
+	}
+	color.Name = name
+
+	//	our own extension wins, it is exact
+	var ext map[string]json.RawMessage
+	if rawExt, ok := token.get("$extensions").(json.RawMessage); ok {
+		if err = json.Unmarshal(rawExt, &ext); err != nil {
+			return
+		}
+	}
+	if rawASE, ok := ext[tokensExtension]; ok {
+		var x tokenExtension
+		if err = json.Unmarshal(rawASE, &x); err != nil {
+			return
+		}
+		if x.Name != "" {
+			color.Name = x.Name
+		}
+		if x.Type != "" {
+			color.Type = x.Type
+		}
+		if x.Model != "" {
+			color.Model, color.Values = x.Model, x.Values
+		}
+	}
+
+	return
+}
+
+// Converts a color $value, following references.
+func (d *tokenDecoder) value(raw json.RawMessage) (color Color, err error) {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		if !strings.HasPrefix(s, "{") {
+			return ParseColor(s)
+		}
+
+		//	a reference to another token
+		ref := strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+		token, ok := d.tokens[ref]
+		if !ok {
+			return color, fmt.Errorf("unknown reference %s", s)
+		}
+		if d.resolving[ref] {
+			return color, fmt.Errorf("circular reference %s", s)
+		}
+		if d.resolving == nil {
+			d.resolving = map[string]bool{}
+		}
+		d.resolving[ref] = true
+		defer delete(d.resolving, ref)
+
+		target, _ := token.get("$value").(json.RawMessage)
+		return d.value(target)
+	}
+
+	var v tokenColor
+	if err = json.Unmarshal(raw, &v); err != nil {
+		return color, errors.New("$value is not a color")
+	}
+
+	//	every color space of the format has a CSS notation
+	components := make([]string, len(v.Components))
+	for i, c := range v.Components {
+		components[i] = fmt.Sprint(c)
+	}
+
+	switch v.ColorSpace {
+	case "":
+		return color, errors.New("$value has no colorSpace")
+	case "srgb", "srgb-linear", "display-p3", "xyz-d65", "xyz-d50":
+		color, err = ParseColor("color(" + v.ColorSpace + " " + strings.Join(components, " ") + ")")
+	case "hsl", "hwb":
+		//	saturation, lightness, whiteness and blackness are percentages
+		if len(components) == 3 {
+			components[1] += "%"
+			components[2] += "%"
+		}
+		color, err = ParseColor(v.ColorSpace + "(" + strings.Join(components, " ") + ")")
+	default:
+		color, err = ParseColor(v.ColorSpace + "(" + strings.Join(components, " ") + ")")
+	}
+
+	//	spaces we don't know still come with a hex fallback
+	if err != nil && v.Hex != "" {
+		color, err = ParseColor(v.Hex)
+	}
+
+	return
+}
@@ -0,0 +1,114 @@
+package ase
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTokensRoundTrip(t *testing.T) {
+	loose := Color{Name: "brand.accent", Model: RGB, Values: []float32{0.5, 0.25, 1}, Type: Global}
+	group := testGroup
+	sampleAse := ASE{Entries: []Entry{&testColors[1], &group, &testColors[4], &loose}}
+
+	b := new(bytes.Buffer)
+	if err := EncodeTokens(sampleAse, b); err != nil {
+		t.Fatal(err)
+	}
+
+	out := b.String()
+	for _, s := range []string{`"$type": "color"`, `"colorSpace": "lab"`, `"brand_accent": {`, `"A Color Group": {`, `"type": "Spot"`, `"hex": "#ff0000"`} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %s in\n%s", s, out)
+		}
+	}
+
+	ase, err := DecodeTokens(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedLoose := []Color{testColors[1], testColors[4], loose}
+	if len(ase.Colors) != len(expectedLoose) {
+		t.Fatalf("expected %d loose colors, got %+v", len(expectedLoose), ase.Colors)
+	}
+	for i, c := range ase.Colors {
+		e := expectedLoose[i]
+		if c.Name != e.Name || c.Model != e.Model || c.Type != e.Type || !closeTo(c.Values, e.Values, 1e-5) {
+			t.Errorf("expected %+v, got %+v", e, c)
+		}
+	}
+
+	// Loose colors keep their place around the group.
+	var names []string
+	for _, entry := range ase.Entries {
+		switch entry := entry.(type) {
+		case *Color:
+			names = append(names, entry.Name)
+		case *Group:
+			names = append(names, entry.Name+"/")
+		}
+	}
+	if expected := []string{"Grayscale", testGroup.Name + "/", "PANTONE P 1-8 C", "brand.accent"}; fmt.Sprint(names) != fmt.Sprint(expected) {
+		t.Errorf("expected entries %q, got %q", expected, names)
+	}
+
+	if len(ase.Groups) != 1 || ase.Groups[0].Name != testGroup.Name || len(ase.Groups[0].Colors) != 3 {
+		t.Fatalf("unexpected groups %+v", ase.Groups)
+	}
+	if c := ase.Groups[0].Colors[2]; c.Name != "Blue" || c.Type != Global || !closeTo(c.Values, []float32{0, 0, 1}, 1e-6) {
+		t.Errorf("unexpected color %+v", c)
+	}
+}
+
+func TestDecodeTokens(t *testing.T) {
+	const tokens = `{
+		"base": {
+			"$type": "color",
+			"red": {"$value": "#ff0000"},
+			"size": {"$type": "dimension", "$value": {"value": 4, "unit": "px"}},
+			"shades": {
+				"dark": {"$value": {"colorSpace": "hsl", "components": [0, 100, 25]}},
+				"wide": {"$value": {"colorSpace": "rec2020", "components": [1, 0, 0], "hex": "#ff0000"}}
+			}
+		},
+		"danger": {"$type": "color", "$value": "{base.red}"},
+		"loop": {"$type": "color", "$value": "{loop}"}
+	}`
+
+	if _, err := DecodeTokens(strings.NewReader(tokens)); err == nil || !strings.Contains(err.Error(), "circular") {
+		t.Fatal("expected a circular reference error, got", err)
+	}
+
+	ase, err := DecodeTokens(strings.NewReader(strings.Replace(tokens, `"{loop}"`, `"oklch(0.7 0.1 200)"`, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ase.Groups) != 2 || ase.Groups[0].Name != "base" || ase.Groups[1].Name != "base/shades" {
+		t.Fatalf("unexpected groups %+v", ase.Groups)
+	}
+	if len(ase.Groups[0].Colors) != 1 || len(ase.Groups[1].Colors) != 2 {
+		t.Fatalf("expected the dimension token to be skipped, got %+v", ase.Groups)
+	}
+	if c := ase.Groups[1].Colors[0]; !closeTo(c.Values, []float32{0.5, 0, 0}, 1e-6) || c.Type != Normal {
+		t.Errorf("unexpected color %+v", c)
+	}
+	if len(ase.Colors) != 2 || ase.Colors[0].Name != "danger" || !closeTo(ase.Colors[0].Values, []float32{1, 0, 0}, 0) {
+		t.Errorf("expected danger to resolve to red, got %+v", ase.Colors)
+	}
+	if ase.Colors[1].Model != LAB {
+		t.Errorf("expected oklch to give a LAB color, got %+v", ase.Colors[1])
+	}
+
+	for _, bad := range []string{
+		`{"base": {"$type": 5, "red": {"$value": "#ff0000"}}}`,
+		`{"red": {"$type": ["color"], "$value": "#ff0000"}}`,
+	} {
+		if _, err := DecodeTokens(strings.NewReader(bad)); !errors.Is(err, ErrInvalidTokens) {
+			t.Error("expected", ErrInvalidTokens, "for", bad, "got", err)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package ase
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	ErrValueCount  = errors.New("ase: wrong number of values for color model")
+	ErrValueRange  = errors.New("ase: color value out of range")
+	ErrNameTooLong = errors.New("ase: name too long")
+)
+
+// The longest name that fits a block, in UTF-16 code units. One more unit is
+// taken by the zero terminator.
+const maxNameLen = math.MaxUint16 - 1
+
+// A FieldError reports a problem with one field of a document.
+type FieldError struct {
+	Path string // location of the field, such as "Groups[2].Colors[5].Values"
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// An ErrorList is the list of problems found by Validate.
+type ErrorList []*FieldError
+
+func (list ErrorList) Error() string {
+	msgs := make([]string, len(list))
+	for i, e := range list {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Allows errors.Is and errors.As to look at every problem in the list.
+func (list ErrorList) Unwrap() []error {
+	errs := make([]error, len(list))
+	for i, e := range list {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Returns the list as an error, or nil if it is empty.
+func (list ErrorList) err() error {
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// Records err at path.
+func (list *ErrorList) add(path string, err error) {
+	*list = append(*list, &FieldError{Path: path, Err: err})
+}
+
+// Checks the color can be encoded: a known model and type, as many values as
+// the model takes and each of them in range, and a name that fits a block.
+// Returns an ErrorList, or nil if the color is valid.
+func (color *Color) Validate() error {
+	var errs ErrorList
+	color.validate("", &errs)
+	return errs.err()
+}
+
+// Checks the group's name and each of its colors. Returns an ErrorList, or nil
+// if the group is valid.
+func (group *Group) Validate() error {
+	var errs ErrorList
+	group.validate("", &errs)
+	return errs.err()
+}
+
+// Checks every color and group Encode would write. Returns an ErrorList, or
+// nil if the document is valid.
+func (ase *ASE) Validate() error {
+	var errs ErrorList
+
+	//	entries decoded from a file point into Colors and Groups, which
+	//	make for friendlier paths
+	paths := map[Entry]string{}
+	for i, entry := range ase.Entries {
+		if _, ok := paths[entry]; !ok {
+			paths[entry] = fmt.Sprintf("Entries[%d].", i)
+		}
+	}
+	for i := range ase.Colors {
+		paths[&ase.Colors[i]] = fmt.Sprintf("Colors[%d].", i)
+	}
+	for i := range ase.Groups {
+		paths[&ase.Groups[i]] = fmt.Sprintf("Groups[%d].", i)
+	}
+
+	for _, entry := range ase.entries() {
+		switch entry := entry.(type) {
+		case *Color:
+			entry.validate(paths[entry], &errs)
+		case *Group:
+			entry.validate(paths[entry], &errs)
+		}
+	}
+
+	return errs.err()
+}
+
+// Adds the color's problems to errs, with paths starting with prefix.
+func (color *Color) validate(prefix string, errs *ErrorList) {
+	if n := nameLen(color.Name); n > maxNameLen {
+		errs.add(prefix+"Name", fmt.Errorf("%w: %d UTF-16 code units, at most %d fit", ErrNameTooLong, n, maxNameLen))
+	}
+	if !color.Type.Valid() {
+		errs.add(prefix+"Type", fmt.Errorf("%w: %q", ErrInvalidColorType, color.Type))
+	}
+	if !color.Model.Valid() {
+		errs.add(prefix+"Model", fmt.Errorf("%w: %q", ErrInvalidColorModel, color.Model))
+		return
+	}
+
+	ranges := color.Model.ranges()
+	if len(color.Values) != len(ranges) {
+		errs.add(prefix+"Values", fmt.Errorf("%w: %s takes %d, got %d", ErrValueCount, color.Model, len(ranges), len(color.Values)))
+		return
+	}
+
+	for i, v := range color.Values {
+		r := ranges[i]
+		if math.IsNaN(float64(v)) || v < r[0] || v > r[1] {
+			errs.add(fmt.Sprintf("%sValues[%d]", prefix, i), fmt.Errorf("%w: %v not in [%v, %v]", ErrValueRange, v, r[0], r[1]))
+		}
+	}
+}
+
+// Adds the group's problems to errs, with paths starting with prefix.
+func (group *Group) validate(prefix string, errs *ErrorList) {
+	if n := nameLen(group.Name); n > maxNameLen {
+		errs.add(prefix+"Name", fmt.Errorf("%w: %d UTF-16 code units, at most %d fit", ErrNameTooLong, n, maxNameLen))
+	}
+
+	paths := map[Entry]string{}
+	for i, entry := range group.Entries {
+		if _, ok := paths[entry]; !ok {
+			paths[entry] = fmt.Sprintf("%sEntries[%d].", prefix, i)
+		}
+	}
+	for i := range group.Colors {
+		paths[&group.Colors[i]] = fmt.Sprintf("%sColors[%d].", prefix, i)
+	}
+
+	for _, entry := range group.entries() {
+		if color, ok := entry.(*Color); ok {
+			color.validate(paths[color], errs)
+		}
+	}
+}
+
+// Returns the allowed range of each of the model's values. RGB, CMYK and Gray
+// components go from 0 to 1. LAB lightness does too, as it is stored divided
+// by 100, while a and b go from -128 to 127.
+func (model ColorModel) ranges() [][2]float32 {
+	unit := [2]float32{0, 1}
+
+	switch model {
+	case RGB:
+		return [][2]float32{unit, unit, unit}
+	case CMYK:
+		return [][2]float32{unit, unit, unit, unit}
+	case LAB:
+		return [][2]float32{unit, {-128, 127}, {-128, 127}}
+	case Gray:
+		return [][2]float32{unit}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package ase
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	sampleAse := ASE{}
+	sampleAse.Colors = testColors
+	sampleAse.Groups = []Group{testGroup, testGroup, testGroup}
+
+	if err := sampleAse.Validate(); err != nil {
+		t.Fatal("expected test colors to be valid, got", err)
+	}
+
+	// Break a color inside the third group without touching the shared test data.
+	broken := append([]Color{}, testGroup.Colors...)
+	broken[1].Values = []float32{0, 1, 0, 0}
+	broken[2].Values = []float32{0, 2, 0}
+	broken[2].Type = "Process"
+	sampleAse.Groups[2].Colors = broken
+
+	err := sampleAse.Validate()
+	list, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("expected an ErrorList, got %#v", err)
+	}
+
+	expected := []struct {
+		path string
+		err  error
+	}{
+		{"Groups[2].Colors[1].Values", ErrValueCount},
+		{"Groups[2].Colors[2].Type", ErrInvalidColorType},
+		{"Groups[2].Colors[2].Values[1]", ErrValueRange},
+	}
+	if len(list) != len(expected) {
+		t.Fatal("expected", len(expected), "errors, got", err)
+	}
+	for i, e := range expected {
+		if list[i].Path != e.path || !errors.Is(list[i], e.err) {
+			t.Errorf("expected %v at %s, got %v", e.err, e.path, list[i])
+		}
+	}
+
+	if !errors.Is(err, ErrValueRange) {
+		t.Error("expected errors.Is to see through the list")
+	}
+
+	if err = Encode(sampleAse, new(bytes.Buffer)); err == nil {
+		t.Error("expected Encode to validate")
+	}
+}
+
+func TestValidateEntries(t *testing.T) {
+	ase, err := DecodeFile("samples/test.ase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ase.Groups[0].Colors[0].Values = nil
+	ase.Entries = append(ase.Entries, &Color{Name: "extra", Model: "HSB", Type: Spot})
+
+	list, _ := ase.Validate().(ErrorList)
+	if len(list) != 2 || list[0].Path != "Groups[0].Colors[0].Values" || list[1].Path != "Entries[6].Model" {
+		t.Error("unexpected errors", list)
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	var palette ASE
+	for i := 0; i < 50000; i++ {
+		palette.Colors = append(palette.Colors, Color{Name: "Color", Model: Gray, Values: []float32{float32(i%100) / 100}, Type: Spot})
+	}
+
+	// Decoded documents have Entries, pointing into Colors.
+	buf := new(bytes.Buffer)
+	if err := Encode(palette, buf); err != nil {
+		b.Fatal(err)
+	}
+	decoded, err := Decode(buf)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := decoded.Validate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}